@@ -0,0 +1,12 @@
+# Warnings and alarms of the last 30 days.
+data "bhyve_event_log" "recent" {
+  since        = timeadd(plantimestamp(), "-720h")
+  min_severity = "warning"
+}
+
+check "no_flow_alarms" {
+  assert {
+    condition     = !contains([for event in data.bhyve_event_log.recent.events : event.type], "flow_sensor_alarm")
+    error_message = "The device raised a flow sensor alarm in the last 30 days."
+  }
+}
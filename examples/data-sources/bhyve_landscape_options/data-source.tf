@@ -0,0 +1,21 @@
+data "bhyve_landscape_options" "all" {}
+
+variable "soil_type" {
+  type    = string
+  default = "clay_loam"
+}
+
+locals {
+  soil_types = { for soil in data.bhyve_landscape_options.all.soil_types : soil.id => soil }
+}
+
+check "soil_type" {
+  assert {
+    condition     = contains(keys(local.soil_types), var.soil_type)
+    error_message = "Unknown soil type ${var.soil_type}."
+  }
+}
+
+output "soil" {
+  value = try(local.soil_types[var.soil_type].name, null)
+}
@@ -0,0 +1,18 @@
+data "bhyve_program" "a" {
+  program = "A"
+}
+
+data "bhyve_zones" "all" {}
+
+output "program_a_gallons_per_week" {
+  value = provider::bhyve::estimate_usage(
+    [for zone in data.bhyve_zones.all.zones : { station = zone.station, flow_rate = coalesce(zone.flow_rate, 0) }],
+    {
+      name        = data.bhyve_program.a.name
+      frequency   = data.bhyve_program.a.frequency
+      start_times = data.bhyve_program.a.start_times
+      run_times   = data.bhyve_program.a.run_times
+    },
+    "gallons",
+  ).per_week
+}
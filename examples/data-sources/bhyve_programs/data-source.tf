@@ -0,0 +1,9 @@
+data "bhyve_programs" "all" {}
+
+# Programs B-hyve does not manage through smart watering, by letter.
+output "manual_programs" {
+  value = {
+    for program in data.bhyve_programs.all.programs : program.program => program.name
+    if !program.smart
+  }
+}
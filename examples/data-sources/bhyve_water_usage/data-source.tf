@@ -0,0 +1,11 @@
+# Monthly water use of the last year, for the HOA report.
+data "bhyve_water_usage" "monthly" {
+  since    = timeadd(plantimestamp(), "-8760h")
+  period   = "month"
+  unit     = "gallons"
+  timezone = "America/Denver"
+}
+
+output "gallons_by_month" {
+  value = { for month in data.bhyve_water_usage.monthly.periods : month.start => month.volume }
+}
@@ -0,0 +1,16 @@
+data "bhyve_weather" "home" {}
+
+locals {
+  tomorrow = formatdate("YYYY-MM-DD", timeadd(plantimestamp(), "24h"))
+}
+
+output "waters_tomorrow" {
+  value = anytrue([
+    for run in data.bhyve_weather.home.upcoming_runs :
+    !run.skip && formatdate("YYYY-MM-DD", run.start_time) == local.tomorrow
+  ])
+}
+
+output "rain_tomorrow_inches" {
+  value = one([for day in data.bhyve_weather.home.forecast : day.precipitation if day.date == local.tomorrow])
+}
@@ -0,0 +1,13 @@
+# Enabled lawn zones watered by program A.
+data "bhyve_zones" "lawns" {
+  name_regex = "(?i)lawn"
+  enabled    = true
+  program    = "A"
+}
+
+resource "bhyve_zone_run" "lawn" {
+  for_each = { for zone in data.bhyve_zones.lawns.zones : zone.name => zone }
+
+  id      = each.value.station
+  minutes = 10
+}
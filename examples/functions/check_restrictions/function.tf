@@ -0,0 +1,27 @@
+locals {
+  lawn = {
+    name = "lawn"
+    frequency = {
+      type           = "days"
+      days           = ["mon", "wed", "fri"]
+      interval       = null
+      interval_start = null
+    }
+    start_times = ["05:00"]
+    run_times = [
+      { station = 1, minutes = 20 },
+      { station = 2, minutes = 15 },
+    ]
+  }
+}
+
+check "watering_restrictions" {
+  assert {
+    condition = length(provider::bhyve::check_restrictions(local.lawn, {
+      house_number       = null
+      allowed_days       = ["mon", "wed", "fri", "sat"]
+      prohibited_windows = [{ start = "10:00", end = "18:00" }]
+    })) == 0
+    error_message = "The lawn program breaks the municipal watering restrictions."
+  }
+}
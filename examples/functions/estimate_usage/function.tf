@@ -0,0 +1,23 @@
+output "lawn_water_usage" {
+  value = provider::bhyve::estimate_usage(
+    [
+      { station = 1, flow_rate = 2.5 },
+      { station = 2, flow_rate = 1.2 },
+    ],
+    {
+      name = "lawn"
+      frequency = {
+        type           = "interval"
+        days           = null
+        interval       = 2
+        interval_start = "2024-06-01"
+      }
+      start_times = ["05:00"]
+      run_times = [
+        { station = 1, minutes = 20 },
+        { station = 2, minutes = 15 },
+      ]
+    },
+    "liters",
+  ).per_month
+}
@@ -0,0 +1,18 @@
+output "watering_calendar" {
+  value = provider::bhyve::to_ical([
+    {
+      name = "lawn"
+      frequency = {
+        type           = "days"
+        days           = ["mon", "wed", "fri"]
+        interval       = null
+        interval_start = null
+      }
+      start_times = ["05:00"]
+      run_times = [
+        { station = 1, minutes = 20 },
+        { station = 2, minutes = 15 },
+      ]
+    },
+  ], "America/Denver")
+}
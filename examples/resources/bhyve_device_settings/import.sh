@@ -0,0 +1,2 @@
+# The ID is the provider's deviceid.
+terraform import bhyve_device_settings.backyard 1a2b3c4d5e6f
@@ -0,0 +1,10 @@
+# Adopts the device the provider is configured for. Destroying this resource
+# leaves the device as it is.
+resource "bhyve_device_settings" "backyard" {
+  name           = "Backyard"
+  timezone       = "America/Denver"
+  latitude       = 39.74
+  longitude      = -104.99
+  units          = "imperial"
+  smart_watering = true
+}
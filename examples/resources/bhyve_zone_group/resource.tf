@@ -0,0 +1,46 @@
+variable "backyard_device_id" {
+  type = string
+}
+
+variable "side_yard_device_id" {
+  type = string
+}
+
+# The front lawn is watered by stations on two controllers. Zones without a
+# device_id are on the device the provider is configured for.
+resource "bhyve_zone_group" "front_lawn" {
+  name = "front lawn"
+  zones = [
+    { device_id = var.backyard_device_id, station = 1, minutes = 10 },
+    { device_id = var.backyard_device_id, station = 2, minutes = 12 },
+    { device_id = var.side_yard_device_id, station = 3, minutes = 8 },
+  ]
+}
+
+# Run the whole group, on both controllers, in one apply.
+resource "bhyve_zone_group_run" "front_lawn" {
+  group = bhyve_zone_group.front_lawn.id
+  zones = bhyve_zone_group.front_lawn.zones
+}
+
+# Estimate the water a twice weekly program of the group's backyard zones
+# would use.
+data "bhyve_zones" "backyard" {}
+
+output "front_lawn_backyard_gallons_per_week" {
+  value = provider::bhyve::estimate_usage(
+    [for zone in data.bhyve_zones.backyard.zones : { station = zone.station, flow_rate = coalesce(zone.flow_rate, 0) }],
+    {
+      name = bhyve_zone_group.front_lawn.name
+      frequency = {
+        type           = "days"
+        days           = ["mon", "thu"]
+        interval       = null
+        interval_start = null
+      }
+      start_times = ["05:00"]
+      run_times   = bhyve_zone_group.front_lawn.devices[var.backyard_device_id].run_times
+    },
+    "gallons",
+  ).per_week
+}
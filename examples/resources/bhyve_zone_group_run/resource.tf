@@ -0,0 +1,17 @@
+resource "bhyve_zone_group" "front_lawn" {
+  name = "front lawn"
+  zones = [
+    { station = 1, minutes = 10 },
+    { station = 2, minutes = 12 },
+  ]
+}
+
+# Starts both zones, one after the other. Changing the group starts it again.
+resource "bhyve_zone_group_run" "front_lawn" {
+  group = bhyve_zone_group.front_lawn.id
+  zones = bhyve_zone_group.front_lawn.zones
+
+  timeouts = {
+    create = "10m"
+  }
+}
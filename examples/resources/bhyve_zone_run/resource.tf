@@ -0,0 +1,32 @@
+resource "bhyve_zone_run" "front_lawn" {
+  id        = 5
+  minutes   = 10
+  flow_rate = 2.5
+
+  # Hose timers behind a bridge can be slow to acknowledge.
+  timeouts {
+    create = "10m"
+  }
+}
+
+# Flush a drip line and hold the apply until the run has finished.
+resource "bhyve_zone_run" "drip_flush" {
+  id                  = 7
+  minutes             = 5
+  wait_for_completion = true
+}
+
+# Water a clay slope in cycles of at most 8 minutes with 20 minute soaks
+# between them. The plan shows the cycles and total_duration.
+resource "bhyve_zone_run" "slope" {
+  id            = 3
+  minutes       = 24
+  cycle_minutes = 8
+  soak_minutes  = 20
+}
+
+# Migrate a run created with the deprecated bhyve_zone resource.
+moved {
+  from = bhyve_zone.front_lawn
+  to   = bhyve_zone_run.front_lawn
+}
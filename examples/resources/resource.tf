@@ -6,9 +6,19 @@ terraform {
   }
 }
 
-provider "bhyve" {}
+provider "bhyve" {
+  run_water_warning_gallons = 50
+
+  # Refuse runs at night, runs over 30 minutes and more than two hours a day.
+  quiet_hours = [
+    { start = "22:00", end = "06:00" },
+  ]
+  max_zone_minutes             = 30
+  max_daily_minutes_per_device = 120
+}
 
 resource "bhyve_zone" "zone" {
-  id      = 5
-  minutes = 5
+  id        = 5
+  minutes   = 5
+  flow_rate = 2.5
 }
\ No newline at end of file
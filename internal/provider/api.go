@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+const (
+	// bhyveEndpoint is the B-hyve REST API.
+	bhyveEndpoint = "https://api.orbitbhyve.com/v1"
+
+	// bhyveEventsURL is the B-hyve events websocket.
+	bhyveEventsURL = "wss://api.orbitbhyve.com/v1/events"
+)
+
+// apiClient makes the B-hyve REST calls that the client library does not
+// cover. It signs in on first use with its own session.
+type apiClient struct {
+	endpoint string
+	email    string
+	password string
+	http     *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// newAPIClient returns an apiClient whose requests are rate limited and, when
+// idempotent, retried by the provider policies.
+func newAPIClient(endpoint, email, password string, data *bhyveProviderData) *apiClient {
+	return &apiClient{
+		endpoint: endpoint,
+		email:    email,
+		password: password,
+		http: &http.Client{
+			Transport: &retryTransport{
+				policy: data.retry,
+				next:   &limitedTransport{limiter: data.limiter},
+			},
+		},
+	}
+}
+
+// apiError is a non-successful response from the B-hyve API.
+type apiError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// session returns the session token, signing in if there is none yet.
+func (a *apiClient) session(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" {
+		return a.token, nil
+	}
+
+	payload := map[string]interface{}{
+		"session": map[string]string{
+			"email":    a.email,
+			"password": a.password,
+		},
+	}
+	var result struct {
+		Token string `json:"orbit_session_token"`
+	}
+	if err := a.send(ctx, http.MethodPost, "/session", "", payload, &result); err != nil {
+		return "", err
+	}
+	if result.Token == "" {
+		return "", fmt.Errorf("POST /session: response has no session token")
+	}
+
+	a.token = result.Token
+	return a.token, nil
+}
+
+// do sends an authenticated request, encoding body and decoding the JSON
+// response into out when they are not nil.
+func (a *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := a.session(ctx)
+	if err != nil {
+		return err
+	}
+	return a.send(ctx, method, path, token, body, out)
+}
+
+func (a *apiClient) send(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader io.Reader
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("orbit-session-token", token)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &apiError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(detail)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestAPIClient(t *testing.T) {
+	var logins int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			logins++
+			var body struct {
+				Session struct{ Email, Password string }
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Session.Email != "me@example.com" || body.Session.Password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"orbit_session_token":"token"}`))
+		case "/devices":
+			if r.Header.Get("orbit-session-token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id":"abc"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`not found`))
+		}
+	}))
+	defer server.Close()
+
+	data := &bhyveProviderData{
+		retry:   retryPolicy{maxRetries: 0, maxElapsed: time.Second},
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	api := newAPIClient(server.URL, "me@example.com", "secret", data)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		var devices []struct{ ID string }
+		if err := api.do(ctx, http.MethodGet, "/devices", nil, &devices); err != nil {
+			t.Fatal(err)
+		}
+		if len(devices) != 1 || devices[0].ID != "abc" {
+			t.Errorf("got devices %+v", devices)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("signed in %d times, want the session reused", logins)
+	}
+
+	err := api.do(ctx, http.MethodGet, "/missing", nil, nil)
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Body != "not found" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+// newFakeAPI returns an apiClient for a server answering each path in
+// routes with its JSON body, and everything else with 404.
+func newFakeAPI(t *testing.T, routes map[string]string) *apiClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/session" {
+			_, _ = w.Write([]byte(`{"orbit_session_token":"token"}`))
+			return
+		}
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	data := &bhyveProviderData{
+		retry:   retryPolicy{maxRetries: 0, maxElapsed: time.Second},
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	return newAPIClient(server.URL, "me@example.com", "secret", data)
+}
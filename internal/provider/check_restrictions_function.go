@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = CheckRestrictionsFunction{}
+)
+
+var timeWindowAttrTypes = map[string]attr.Type{
+	"start": types.StringType,
+	"end":   types.StringType,
+}
+
+var restrictionsAttrTypes = map[string]attr.Type{
+	"house_number":       types.Int64Type,
+	"allowed_days":       types.ListType{ElemType: types.StringType},
+	"prohibited_windows": types.ListType{ElemType: types.ObjectType{AttrTypes: timeWindowAttrTypes}},
+}
+
+// restrictionsModel describes municipal watering restrictions. Any attribute
+// may be null to leave that restriction out.
+type restrictionsModel struct {
+	HouseNumber       *int64            `tfsdk:"house_number"`
+	AllowedDays       []string          `tfsdk:"allowed_days"`
+	ProhibitedWindows []timeWindowModel `tfsdk:"prohibited_windows"`
+}
+
+type timeWindowModel struct {
+	Start string `tfsdk:"start"`
+	End   string `tfsdk:"end"`
+}
+
+func NewCheckRestrictionsFunction() function.Function {
+	return CheckRestrictionsFunction{}
+}
+
+type CheckRestrictionsFunction struct{}
+
+func (r CheckRestrictionsFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "check_restrictions"
+}
+
+func (r CheckRestrictionsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check a program against watering restrictions",
+		MarkdownDescription: "Evaluates a program's frequency and start times against odd/even house number, " +
+			"day of week and time of day restrictions. Returns a list of violations, empty when the program complies.",
+		Parameters: []function.Parameter{
+			function.ObjectParameter{
+				Name: "schedule",
+				MarkdownDescription: "Program with `name`, `frequency` (`type` of `days`, `interval`, `odd` or `even`, " +
+					"`days`, `interval` and `interval_start`), `start_times` in HH:MM and `run_times` " +
+					"(`station` and `minutes`).",
+				AttributeTypes: programAttrTypes,
+			},
+			function.ObjectParameter{
+				Name: "rules",
+				MarkdownDescription: "Restrictions with `house_number`, `allowed_days` and `prohibited_windows` " +
+					"(`start` and `end` in HH:MM). Set an attribute to null to skip that restriction.",
+				AttributeTypes: restrictionsAttrTypes,
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (r CheckRestrictionsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var program programModel
+	var rules restrictionsModel
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &program, &rules))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if err := program.validate(); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	if err := rules.validate(); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, checkRestrictions(program, rules)))
+}
+
+func (r restrictionsModel) validate() error {
+	for _, d := range r.AllowedDays {
+		if _, err := parseWeekday(d); err != nil {
+			return err
+		}
+	}
+	for _, w := range r.ProhibitedWindows {
+		if _, err := parseStartTime(w.Start); err != nil {
+			return err
+		}
+		if _, err := parseStartTime(w.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRestrictions returns a human readable message for every way the
+// program breaks the rules. Both inputs must already be validated.
+func checkRestrictions(program programModel, rules restrictionsModel) []string {
+	violations := []string{}
+
+	if rules.HouseNumber != nil {
+		parity := frequencyEven
+		if *rules.HouseNumber%2 != 0 {
+			parity = frequencyOdd
+		}
+		if program.Frequency.Type != parity {
+			violations = append(violations, fmt.Sprintf(
+				"house number %d may only water on %s days, but the program uses a %q frequency",
+				*rules.HouseNumber, parity, program.Frequency.Type))
+		}
+	}
+
+	if len(rules.AllowedDays) > 0 {
+		allowed := make(map[time.Weekday]bool, len(rules.AllowedDays))
+		for _, d := range rules.AllowedDays {
+			wd, _ := parseWeekday(d)
+			allowed[wd] = true
+		}
+		var denied []string
+		for _, wd := range program.Frequency.weekdays() {
+			if !allowed[wd] {
+				denied = append(denied, strings.ToLower(wd.String()))
+			}
+		}
+		if len(denied) > 0 {
+			violations = append(violations, fmt.Sprintf(
+				"the program can water on %s, which is not an allowed day",
+				strings.Join(denied, ", ")))
+		}
+	}
+
+	duration := int(program.runMinutes())
+	for _, s := range program.StartTimes {
+		start, _ := parseStartTime(s)
+		for _, w := range rules.ProhibitedWindows {
+			if overlapsWindow(start, duration, w) {
+				violations = append(violations, fmt.Sprintf(
+					"the run starting at %s ends at %s and overlaps the prohibited window %s-%s",
+					s, formatClock(start+duration), w.Start, w.End))
+			}
+		}
+	}
+
+	return violations
+}
+
+// overlapsWindow reports whether a run of duration minutes starting start
+// minutes after midnight overlaps the window. Windows whose end is not after
+// their start wrap past midnight.
+func overlapsWindow(start, duration int, w timeWindowModel) bool {
+	ws, _ := parseStartTime(w.Start)
+	we, _ := parseStartTime(w.End)
+	if we <= ws {
+		we += 24 * 60
+	}
+	end := start + duration
+	if duration == 0 {
+		end = start + 1
+	}
+	for _, shift := range []int{-24 * 60, 0, 24 * 60} {
+		if start < we+shift && ws+shift < end {
+			return true
+		}
+	}
+	return false
+}
+
+// formatClock formats minutes after midnight as HH:MM, wrapping at midnight.
+func formatClock(minutes int) string {
+	minutes %= 24 * 60
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
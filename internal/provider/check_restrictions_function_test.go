@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckRestrictions(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int64) *int64 { return &n }
+
+	lawn := programModel{
+		Name:       str("lawn"),
+		Frequency:  frequencyModel{Type: frequencyDays, Days: []string{"mon", "Wednesday"}},
+		StartTimes: []string{"05:00"},
+		RunTimes:   []runTimeModel{{Station: 1, Minutes: 20}, {Station: 2, Minutes: 15}},
+	}
+
+	cases := map[string]struct {
+		program programModel
+		rules   restrictionsModel
+		want    []string
+	}{
+		"no rules": {
+			program: lawn,
+			want:    []string{},
+		},
+		"allowed days": {
+			program: lawn,
+			rules:   restrictionsModel{AllowedDays: []string{"mon", "tue"}},
+			want:    []string{"the program can water on wednesday, which is not an allowed day"},
+		},
+		"house number parity": {
+			program: lawn,
+			rules:   restrictionsModel{HouseNumber: num(221)},
+			want:    []string{`house number 221 may only water on odd days, but the program uses a "days" frequency`},
+		},
+		"matching parity": {
+			program: programModel{
+				Frequency:  frequencyModel{Type: frequencyEven},
+				StartTimes: []string{"05:00"},
+			},
+			rules: restrictionsModel{HouseNumber: num(14)},
+			want:  []string{},
+		},
+		"weekly interval": {
+			program: programModel{
+				Frequency:  frequencyModel{Type: frequencyInterval, Interval: num(14), IntervalStart: str("2024-06-03")},
+				StartTimes: []string{"05:00"},
+			},
+			rules: restrictionsModel{AllowedDays: []string{"mon"}},
+			want:  []string{},
+		},
+		"prohibited window": {
+			program: programModel{
+				Frequency:  frequencyModel{Type: frequencyOdd},
+				StartTimes: []string{"09:30", "20:00"},
+				RunTimes:   []runTimeModel{{Station: 1, Minutes: 45}},
+			},
+			rules: restrictionsModel{ProhibitedWindows: []timeWindowModel{{Start: "10:00", End: "18:00"}}},
+			want:  []string{"the run starting at 09:30 ends at 10:15 and overlaps the prohibited window 10:00-18:00"},
+		},
+		"window wrapping midnight": {
+			program: programModel{
+				Frequency:  frequencyModel{Type: frequencyOdd},
+				StartTimes: []string{"23:30", "01:30"},
+				RunTimes:   []runTimeModel{{Station: 1, Minutes: 20}},
+			},
+			rules: restrictionsModel{ProhibitedWindows: []timeWindowModel{{Start: "23:00", End: "01:00"}}},
+			want:  []string{"the run starting at 23:30 ends at 23:50 and overlaps the prohibited window 23:00-01:00"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.program.validate(); err != nil {
+				t.Fatalf("unexpected validation error: %s", err)
+			}
+			got := checkRestrictions(tc.program, tc.rules)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProgramValidate(t *testing.T) {
+	cases := map[string]programModel{
+		"unknown frequency": {Frequency: frequencyModel{Type: "weekly"}, StartTimes: []string{"05:00"}},
+		"missing days":      {Frequency: frequencyModel{Type: frequencyDays}, StartTimes: []string{"05:00"}},
+		"bad day":           {Frequency: frequencyModel{Type: frequencyDays, Days: []string{"funday"}}, StartTimes: []string{"05:00"}},
+		"missing interval":  {Frequency: frequencyModel{Type: frequencyInterval}, StartTimes: []string{"05:00"}},
+		"no start times":    {Frequency: frequencyModel{Type: frequencyOdd}},
+		"bad start time":    {Frequency: frequencyModel{Type: frequencyOdd}, StartTimes: []string{"5:00pm"}},
+		"duplicate station": {
+			Frequency:  frequencyModel{Type: frequencyOdd},
+			StartTimes: []string{"05:00"},
+			RunTimes:   []runTimeModel{{Station: 1, Minutes: 5}, {Station: 1, Minutes: 5}},
+		},
+	}
+
+	for name, program := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := program.validate(); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
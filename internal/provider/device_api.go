@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiDevice is a device as returned by the B-hyve devices endpoint.
+type apiDevice struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	NumStations int64     `json:"num_stations"`
+	Zones       []apiZone `json:"zones"`
+	// ManualPresetRuntimeSec is the default runtime of a manual run.
+	ManualPresetRuntimeSec int64        `json:"manual_preset_runtime_sec"`
+	Timezone               apiTimezone  `json:"timezone"`
+	Location               *apiLocation `json:"location"`
+	// Units is "imperial" or "metric".
+	Units string `json:"units"`
+	// WaterSenseMode is "auto" when smart watering is on and "off" when not.
+	WaterSenseMode string `json:"water_sense_mode"`
+}
+
+// apiTimezone is the time zone a device runs its programs in.
+type apiTimezone struct {
+	Name string `json:"timezone_name"`
+}
+
+// apiLocation is where a device is, which sets its weather forecast.
+type apiLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// apiZone is a station of a device. Pointer fields are absent on devices
+// that do not report them.
+type apiZone struct {
+	Station              int64    `json:"station"`
+	Name                 string   `json:"name"`
+	Enabled              *bool    `json:"enabled"`
+	SmartWateringEnabled bool     `json:"smart_watering_enabled"`
+	FlowRate             *float64 `json:"flow_rate"`
+	CropType             string   `json:"crop_type"`
+	SoilType             string   `json:"soil_type"`
+	NozzleType           string   `json:"nozzle_type"`
+	SprinklerType        string   `json:"sprinkler_type"`
+	SlopeType            string   `json:"slope_type"`
+	ExposureType         string   `json:"exposure_type"`
+}
+
+// enabled reports whether the zone is enabled. Zones are enabled unless the
+// device says otherwise.
+func (z apiZone) enabled() bool {
+	return z.Enabled == nil || *z.Enabled
+}
+
+// apiProgram is a watering program of a device.
+type apiProgram struct {
+	ID       string `json:"id"`
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	// Program is the program slot, a lowercase letter.
+	Program    string       `json:"program"`
+	Enabled    bool         `json:"enabled"`
+	StartTimes []string     `json:"start_times"`
+	RunTimes   []apiRunTime `json:"run_times"`
+	Frequency  apiFrequency `json:"frequency"`
+	// Budget is the percentage the run times are scaled by.
+	Budget  *int64 `json:"budget"`
+	IsSmart bool   `json:"is_smart_program"`
+}
+
+// apiRunTime is the run of one station within a program.
+type apiRunTime struct {
+	Station int64 `json:"station"`
+	// RunTime is in minutes.
+	RunTime int64 `json:"run_time"`
+}
+
+// apiFrequency is when a program runs.
+type apiFrequency struct {
+	Type          string  `json:"type"`
+	Days          []int64 `json:"days"`
+	Interval      *int64  `json:"interval"`
+	IntervalStart string  `json:"interval_start_time"`
+}
+
+// letter returns the program slot as used in configuration, such as "A".
+func (p apiProgram) letter() string {
+	return strings.ToUpper(p.Program)
+}
+
+// hasStation reports whether the program waters station.
+func (p apiProgram) hasStation(station int64) bool {
+	for _, run := range p.RunTimes {
+		if run.Station == station {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStation reports whether the device has station. Devices that list no
+// zones are checked against their station count.
+func (d *apiDevice) hasStation(station int64) bool {
+	if len(d.Zones) == 0 {
+		return station >= 1 && station <= d.NumStations
+	}
+	for _, zone := range d.Zones {
+		if zone.Station == station {
+			return true
+		}
+	}
+	return false
+}
+
+// apiWateringEvent is an entry of a device's watering history.
+type apiWateringEvent struct {
+	StartTime  string          `json:"start_time"`
+	Irrigation []apiIrrigation `json:"irrigation"`
+}
+
+// apiIrrigation is the watering of one station within a watering event.
+type apiIrrigation struct {
+	Station        int64   `json:"station"`
+	StartTime      string  `json:"start_time"`
+	RunTime        float64 `json:"run_time"`
+	WaterVolumeGal float64 `json:"water_volume_gal"`
+	Program        string  `json:"program"`
+}
+
+// apiLogEvent is an entry of a device's event log.
+type apiLogEvent struct {
+	ID        string `json:"id"`
+	Event     string `json:"event"`
+	Timestamp string `json:"timestamp"`
+	// Station is absent on events about the whole device.
+	Station *int64 `json:"station"`
+	Message string `json:"message"`
+}
+
+// apiWeather is the forecast smart watering uses for a device, and the runs
+// it plans from it. Forecast values are in inches, degrees Fahrenheit and
+// miles per hour, and are absent when the forecast lacks them.
+type apiWeather struct {
+	Forecast []apiForecastDay `json:"forecast"`
+	Upcoming []apiPlannedRun  `json:"upcoming_runs"`
+}
+
+// apiForecastDay is the forecast for one day.
+type apiForecastDay struct {
+	Date                     string   `json:"date"`
+	Precipitation            *float64 `json:"precip"`
+	PrecipitationProbability *float64 `json:"precip_probability"`
+	HighTemp                 *float64 `json:"high_temp"`
+	LowTemp                  *float64 `json:"low_temp"`
+	WindSpeed                *float64 `json:"wind_speed"`
+}
+
+// apiPlannedRun is an upcoming program run and whether the controller skips
+// it.
+type apiPlannedRun struct {
+	Program    string `json:"program"`
+	StartTime  string `json:"start_time"`
+	Skip       bool   `json:"skip"`
+	SkipReason string `json:"skip_reason"`
+}
+
+// device returns the device with the given ID.
+func (a *apiClient) device(ctx context.Context, deviceID string) (*apiDevice, error) {
+	var device apiDevice
+	if err := a.do(ctx, http.MethodGet, "/devices/"+url.PathEscape(deviceID), nil, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// programs returns the watering programs of a device.
+func (a *apiClient) programs(ctx context.Context, deviceID string) ([]apiProgram, error) {
+	var programs []apiProgram
+	path := "/sprinkler_timer_programs?device_id=" + url.QueryEscape(deviceID)
+	if err := a.do(ctx, http.MethodGet, path, nil, &programs); err != nil {
+		return nil, err
+	}
+	return programs, nil
+}
+
+// wateringEvents returns the watering history of a device.
+func (a *apiClient) wateringEvents(ctx context.Context, deviceID string) ([]apiWateringEvent, error) {
+	var events []apiWateringEvent
+	if err := a.do(ctx, http.MethodGet, "/watering_events/"+url.PathEscape(deviceID), nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// weather returns the forecast and planned runs of a device.
+func (a *apiClient) weather(ctx context.Context, deviceID string) (*apiWeather, error) {
+	var weather apiWeather
+	if err := a.do(ctx, http.MethodGet, "/weather_forecasts/"+url.PathEscape(deviceID), nil, &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// eventLog returns the event log of a device.
+func (a *apiClient) eventLog(ctx context.Context, deviceID string) ([]apiLogEvent, error) {
+	var events []apiLogEvent
+	path := "/event_logs?device_id=" + url.QueryEscape(deviceID)
+	if err := a.do(ctx, http.MethodGet, path, nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// lastRun returns the most recent watering of station in events, and whether
+// there is one.
+func lastRun(events []apiWateringEvent, station int64) (time.Time, apiIrrigation, bool) {
+	var last time.Time
+	var run apiIrrigation
+	for _, ev := range events {
+		for _, irrigation := range ev.Irrigation {
+			if irrigation.Station != station {
+				continue
+			}
+			start := irrigation.StartTime
+			if start == "" {
+				start = ev.StartTime
+			}
+			t, err := time.Parse(time.RFC3339, start)
+			if err != nil || !t.After(last) {
+				continue
+			}
+			last, run = t, irrigation
+		}
+	}
+	return last, run, !last.IsZero()
+}
+
+// isNotFound reports whether err is a 404 response from the B-hyve API.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
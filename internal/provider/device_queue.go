@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// deviceQueues orders commands to each device. It is shared by every
+// provider instance in the process, so aliased providers configured for the
+// same device are serialized too.
+var deviceQueues = newCommandQueues()
+
+// commandQueues runs one command at a time per device, in the order they
+// arrive, while commands for different devices run in parallel.
+type commandQueues struct {
+	mu     sync.Mutex
+	queues map[string]*commandQueue
+}
+
+// commandQueue is the queue of one device. Only devices with a command
+// running have one.
+type commandQueue struct {
+	// waiters are the commands waiting for the device, oldest first. Each
+	// channel is closed when its command is handed the device.
+	waiters []chan struct{}
+}
+
+func newCommandQueues() *commandQueues {
+	return &commandQueues{queues: make(map[string]*commandQueue)}
+}
+
+// run waits for the commands to the device that arrived before it and runs
+// fn. The device is handed to the waiters one by one, oldest first.
+func (q *commandQueues) run(ctx context.Context, deviceID string, fn func() error) error {
+	q.mu.Lock()
+	queue, busy := q.queues[deviceID]
+	if !busy {
+		q.queues[deviceID] = &commandQueue{}
+		q.mu.Unlock()
+	} else {
+		turn := make(chan struct{})
+		queue.waiters = append(queue.waiters, turn)
+		q.mu.Unlock()
+
+		tflog.Debug(ctx, "Waiting for earlier commands to the device", map[string]interface{}{
+			"device_id": deviceID,
+		})
+		select {
+		case <-turn:
+		case <-ctx.Done():
+			if !q.leave(deviceID, turn) {
+				// The device was handed over as ctx ended, so it is
+				// passed on to the next waiter.
+				q.release(deviceID)
+			}
+			return ctx.Err()
+		}
+	}
+	defer q.release(deviceID)
+
+	return fn()
+}
+
+// leave removes turn from the waiters of the device. It reports false if
+// turn was already handed the device.
+func (q *commandQueues) leave(deviceID string, turn chan struct{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := q.queues[deviceID]
+	for i, waiter := range queue.waiters {
+		if waiter == turn {
+			queue.waiters = append(queue.waiters[:i], queue.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// release hands the device to its oldest waiter, or frees it when nothing
+// is waiting.
+func (q *commandQueues) release(deviceID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := q.queues[deviceID]
+	if len(queue.waiters) == 0 {
+		delete(q.queues, deviceID)
+		return
+	}
+	close(queue.waiters[0])
+	queue.waiters = queue.waiters[1:]
+}
+
+// command runs fn with exclusive use of the configured device. fn should
+// make its calls through call and return once the device has acknowledged
+// them, so the next command sees their effect.
+func (d *bhyveProviderData) command(ctx context.Context, fn func() error) error {
+	return deviceQueues.run(ctx, d.deviceID, fn)
+}
+
+// watch subscribes to the events of the configured device. Subscribe before
+// sending a command so its acknowledgement cannot be missed, and close the
+// subscription when done with it.
+func (d *bhyveProviderData) watch(ctx context.Context) (*eventSubscription, error) {
+	sub, err := d.events.subscribe(ctx, d.deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to device events: %w", err)
+	}
+	return sub, nil
+}
+
+// acknowledged runs fn and waits on sub for the device to report an event
+// matching ack, which it returns. How long to wait is bounded by ctx, which
+// carries the resource timeout.
+func acknowledged(ctx context.Context, sub *eventSubscription, ack func(deviceEvent) bool, fn func() error) (deviceEvent, error) {
+	if err := fn(); err != nil {
+		return deviceEvent{}, err
+	}
+	ev, err := sub.waitFor(ctx, ack)
+	if err != nil {
+		return deviceEvent{}, fmt.Errorf("waiting for the device to acknowledge: %w", err)
+	}
+	return ev, nil
+}
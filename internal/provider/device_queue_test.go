@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCommandQueues(t *testing.T) {
+	q := newCommandQueues()
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = q.run(context.Background(), "device-a", func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if maxRunning != 1 {
+		t.Errorf("%d commands ran at once on one device", maxRunning)
+	}
+
+	// A command to another device is not held up by a busy device.
+	busy, release := make(chan struct{}), make(chan struct{})
+	go q.run(context.Background(), "device-a", func() error {
+		close(busy)
+		<-release
+		return nil
+	})
+	<-busy
+	done := make(chan struct{})
+	go func() {
+		_ = q.run(context.Background(), "device-b", func() error { return nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("command to device-b waited for device-a")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.run(ctx, "device-a", func() error { return nil }); err != context.DeadlineExceeded {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+	close(release)
+}
+
+func TestCommandQueuesOrder(t *testing.T) {
+	q := newCommandQueues()
+	waiting := func() int {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.queues["device-a"].waiters)
+	}
+
+	busy, release := make(chan struct{}), make(chan struct{})
+	go q.run(context.Background(), "device-a", func() error {
+		close(busy)
+		<-release
+		return nil
+	})
+	<-busy
+
+	// Queue the commands one at a time, with one that gives up waiting in
+	// the middle.
+	var order []int
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 5; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runCtx := context.Background()
+			if i == 2 {
+				runCtx = ctx
+			}
+			_ = q.run(runCtx, "device-a", func() error {
+				order = append(order, i)
+				return nil
+			})
+		}()
+		for deadline := time.Now().Add(time.Second); waiting() != i+1; {
+			if time.Now().After(deadline) {
+				t.Fatalf("command %d was not queued", i)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	cancel()
+	for deadline := time.Now().Add(time.Second); waiting() != 4; {
+		if time.Now().After(deadline) {
+			t.Fatal("the cancelled command stayed queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+	if want := []int{0, 1, 3, 4}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got commands run in order %v, want %v", order, want)
+	}
+	if len(q.queues) != 0 {
+		t.Errorf("got queues %v left for idle devices", q.queues)
+	}
+}
+
+func TestAcknowledged(t *testing.T) {
+	f := newFakeEvents(t)
+	d := &bhyveProviderData{deviceID: "device-a", events: f.hub()}
+
+	sub, err := d.watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	conn, _ := f.accept(t)
+
+	// The device acknowledges the command.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ev, err := acknowledged(ctx, sub, wateringStarted(2), func() error {
+		return conn.WriteJSON(map[string]interface{}{
+			"event":           eventWateringInProgress,
+			"device_id":       "device-a",
+			"current_station": 2,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != eventWateringInProgress {
+		t.Errorf("got %+v", ev)
+	}
+
+	// The device stays silent until the timeout.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = acknowledged(ctx, sub, wateringStarted(2), func() error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+
+	// A failed command is not waited on.
+	failed := errors.New("failed")
+	_, err = acknowledged(context.Background(), sub, wateringStarted(2), func() error { return failed })
+	if err != failed {
+		t.Errorf("got %v, want the command error", err)
+	}
+}
@@ -0,0 +1,431 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/float64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Unit preferences of a device.
+const (
+	unitsImperial = "imperial"
+	unitsMetric   = "metric"
+)
+
+// Water sense modes of a device, which turn smart watering on and off.
+const (
+	waterSenseAuto = "auto"
+	waterSenseOff  = "off"
+)
+
+// defaultDeviceSettingsTimeout bounds reading and updating the settings when
+// the timeouts block leaves it out.
+const defaultDeviceSettingsTimeout = 2 * time.Minute
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                     = &deviceSettingsResource{}
+	_ resource.ResourceWithConfigure        = &deviceSettingsResource{}
+	_ resource.ResourceWithImportState      = &deviceSettingsResource{}
+	_ resource.ResourceWithConfigValidators = &deviceSettingsResource{}
+)
+
+// NewDeviceSettingsResource returns the resource managing the settings of the
+// configured device.
+func NewDeviceSettingsResource() resource.Resource {
+	return &deviceSettingsResource{}
+}
+
+// deviceSettingsResource is the resource implementation.
+type deviceSettingsResource struct {
+	data *bhyveProviderData
+}
+
+type deviceSettingsResourceModel struct {
+	ID            types.String   `tfsdk:"id"`
+	Name          types.String   `tfsdk:"name"`
+	Timezone      types.String   `tfsdk:"timezone"`
+	Latitude      types.Float64  `tfsdk:"latitude"`
+	Longitude     types.Float64  `tfsdk:"longitude"`
+	Units         types.String   `tfsdk:"units"`
+	SmartWatering types.Bool     `tfsdk:"smart_watering"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+// Metadata returns the resource type name.
+func (r *deviceSettingsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_device_settings"
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *deviceSettingsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.data = data
+}
+
+// Schema defines the schema for the resource.
+func (r *deviceSettingsResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the settings of the device the provider is configured for. The device must already " +
+			"exist: creating the resource adopts it and destroying it only removes it from state. Settings left " +
+			"out of the configuration are read from the device and left alone.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "ID of the device, the provider's deviceid.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Display name of the device.",
+				Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timezone": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "IANA time zone the device runs its programs in, such as \"America/Denver\".",
+				Validators:  []validator.String{timeZoneValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"latitude": schema.Float64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Latitude of the device, which sets its weather forecast. Set together with longitude.",
+				Validators: []validator.Float64{
+					float64validator.Between(-90, 90),
+				},
+				PlanModifiers: []planmodifier.Float64{
+					float64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"longitude": schema.Float64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Longitude of the device. Set together with latitude.",
+				Validators: []validator.Float64{
+					float64validator.Between(-180, 180),
+				},
+				PlanModifiers: []planmodifier.Float64{
+					float64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"units": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Units the B-hyve app shows, \"imperial\" or \"metric\".",
+				Validators:  []validator.String{stringvalidator.OneOf(unitsImperial, unitsMetric)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"smart_watering": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether smart watering, B-hyve's WaterSense mode, adjusts the device's programs.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create:            true,
+				Read:              true,
+				Update:            true,
+				Delete:            true,
+				DeleteDescription: stateOnlyTimeout("Deleting the device settings"),
+			}),
+		},
+	}
+}
+
+// ConfigValidators returns the checks that span several attributes.
+func (r *deviceSettingsResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.RequiredTogether(
+			path.MatchRoot("latitude"),
+			path.MatchRoot("longitude"),
+		),
+	}
+}
+
+// Create adopts the device and applies the configured settings.
+func (r *deviceSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "adopt the device settings") {
+		return
+	}
+
+	var plan deviceSettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultDeviceSettingsTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	device, err := r.data.api.device(ctx, r.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Device Settings",
+			fmt.Sprintf("Could not read device %s: %s", r.data.deviceID, err),
+		)
+		return
+	}
+
+	current := deviceSettings(r.data.deviceID, device)
+	if err := r.update(ctx, current, plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Device Settings",
+			fmt.Sprintf("Could not update device %s: %s", r.data.deviceID, err),
+		)
+		return
+	}
+	if r.heldBack(&resp.Diagnostics, current, plan) {
+		return
+	}
+
+	state := appliedSettings(current, plan)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *deviceSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state deviceSettingsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultDeviceSettingsTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	device, err := r.data.api.device(ctx, state.ID.ValueString())
+	if isNotFound(err) {
+		tflog.Warn(ctx, "Device no longer exists, removing its settings from state", map[string]interface{}{
+			"device_id": state.ID.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Device Settings",
+			fmt.Sprintf("Could not read device %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+
+	refreshed := deviceSettings(state.ID.ValueString(), device)
+	refreshed.Timeouts = state.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, refreshed)...)
+}
+
+// Update changes the settings that differ from the state.
+func (r *deviceSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "update the device settings") {
+		return
+	}
+
+	var plan, state deviceSettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultDeviceSettingsTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	if err := r.update(ctx, state, plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Device Settings",
+			fmt.Sprintf("Could not update device %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	if r.heldBack(&resp.Diagnostics, state, plan) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, appliedSettings(state, plan))...)
+}
+
+// Delete removes the settings from state. The device itself is left as it
+// is.
+func (r *deviceSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	r.data.denyWrite(&resp.Diagnostics, "release the device settings")
+}
+
+// ImportState adopts the configured device by its ID.
+func (r *deviceSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID != r.data.deviceID {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("The provider is configured for device %s, so only its settings can be imported, not those of %s.", r.data.deviceID, req.ID),
+		)
+		return
+	}
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// update sends the settings of desired that differ from current, if any.
+func (r *deviceSettingsResource) update(ctx context.Context, current, desired deviceSettingsResourceModel) error {
+	changes := settingsChanges(current, desired)
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.data.command(ctx, func() error {
+		return r.data.send(ctx, updateDeviceCommand(ctx, r.data.api, r.data.deviceID, changes))
+	})
+}
+
+// heldBack reports whether a dry run logged the settings of desired that
+// differ from current instead of sending them. The device then still has
+// current, which Terraform does not accept as the result of applying
+// desired, so it is reported as an error and the state is left as it was.
+func (r *deviceSettingsResource) heldBack(diags *diag.Diagnostics, current, desired deviceSettingsResourceModel) bool {
+	if r.data.dryRun == nil || len(settingsChanges(current, desired)) == 0 {
+		return false
+	}
+	diags.AddError(
+		"Device Settings Not Applied",
+		fmt.Sprintf("The provider is in dry_run mode, so the changes to device %s were logged instead of sent. "+
+			"The state is left as it was until the settings are applied.", r.data.deviceID),
+	)
+	return true
+}
+
+// deviceSettings maps a device read from the API to the resource model.
+func deviceSettings(deviceID string, device *apiDevice) deviceSettingsResourceModel {
+	model := deviceSettingsResourceModel{
+		ID:            types.StringValue(deviceID),
+		Name:          types.StringValue(device.Name),
+		Timezone:      optionalString(device.Timezone.Name),
+		Latitude:      types.Float64Null(),
+		Longitude:     types.Float64Null(),
+		Units:         optionalString(device.Units),
+		SmartWatering: types.BoolNull(),
+	}
+	if device.Location != nil {
+		model.Latitude = types.Float64Value(device.Location.Latitude)
+		model.Longitude = types.Float64Value(device.Location.Longitude)
+	}
+	if device.WaterSenseMode != "" {
+		model.SmartWatering = types.BoolValue(device.WaterSenseMode != waterSenseOff)
+	}
+	return model
+}
+
+// settingsChanges returns the fields of the device update request for the
+// settings desired sets to something other than current.
+func settingsChanges(current, desired deviceSettingsResourceModel) map[string]interface{} {
+	changed := func(want, have attr.Value) bool {
+		return !want.IsNull() && !want.IsUnknown() && !want.Equal(have)
+	}
+
+	changes := map[string]interface{}{}
+	if changed(desired.Name, current.Name) {
+		changes["name"] = desired.Name.ValueString()
+	}
+	if changed(desired.Timezone, current.Timezone) {
+		changes["timezone"] = map[string]string{"timezone_name": desired.Timezone.ValueString()}
+	}
+	if changed(desired.Latitude, current.Latitude) || changed(desired.Longitude, current.Longitude) {
+		changes["location"] = apiLocation{
+			Latitude:  desired.Latitude.ValueFloat64(),
+			Longitude: desired.Longitude.ValueFloat64(),
+		}
+	}
+	if changed(desired.Units, current.Units) {
+		changes["units"] = desired.Units.ValueString()
+	}
+	if changed(desired.SmartWatering, current.SmartWatering) {
+		mode := waterSenseOff
+		if desired.SmartWatering.ValueBool() {
+			mode = waterSenseAuto
+		}
+		changes["water_sense_mode"] = mode
+	}
+	return changes
+}
+
+// appliedSettings returns current with the settings desired sets, which is
+// what the device holds once they are sent.
+func appliedSettings(current, desired deviceSettingsResourceModel) deviceSettingsResourceModel {
+	known := func(v attr.Value) bool {
+		return !v.IsNull() && !v.IsUnknown()
+	}
+
+	applied := current
+	applied.Timeouts = desired.Timeouts
+	if known(desired.Name) {
+		applied.Name = desired.Name
+	}
+	if known(desired.Timezone) {
+		applied.Timezone = desired.Timezone
+	}
+	if known(desired.Latitude) && known(desired.Longitude) {
+		applied.Latitude, applied.Longitude = desired.Latitude, desired.Longitude
+	}
+	if known(desired.Units) {
+		applied.Units = desired.Units
+	}
+	if known(desired.SmartWatering) {
+		applied.SmartWatering = desired.SmartWatering
+	}
+	return applied
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/time/rate"
+)
+
+func TestDeviceSettings(t *testing.T) {
+	var device apiDevice
+	if err := json.Unmarshal([]byte(`{"id":"abc","name":"Backyard","timezone":{"timezone_name":"America/Denver"},
+		"location":{"latitude":39.7,"longitude":-105},"units":"imperial","water_sense_mode":"auto"}`), &device); err != nil {
+		t.Fatal(err)
+	}
+	current := deviceSettings("abc", &device)
+	if current.Name.ValueString() != "Backyard" || current.Timezone.ValueString() != "America/Denver" ||
+		current.Latitude.ValueFloat64() != 39.7 || current.Units.ValueString() != "imperial" || !current.SmartWatering.ValueBool() {
+		t.Errorf("got %+v", current)
+	}
+	if bare := deviceSettings("abc", &apiDevice{Name: "New"}); !bare.Timezone.IsNull() || !bare.Latitude.IsNull() || !bare.SmartWatering.IsNull() {
+		t.Errorf("got %+v for a device reporting no settings", bare)
+	}
+
+	// Only settings that are set and differ are sent.
+	desired := deviceSettingsResourceModel{
+		Name:          types.StringValue("Backyard"),
+		Timezone:      types.StringUnknown(),
+		Latitude:      types.Float64Value(39.7),
+		Longitude:     types.Float64Value(-104.9),
+		Units:         types.StringNull(),
+		SmartWatering: types.BoolValue(false),
+	}
+	changes := settingsChanges(current, desired)
+	if len(changes) != 2 || changes["water_sense_mode"] != "off" || changes["location"] != (apiLocation{Latitude: 39.7, Longitude: -104.9}) {
+		t.Errorf("got changes %v", changes)
+	}
+
+	applied := appliedSettings(current, desired)
+	if applied.Timezone.ValueString() != "America/Denver" || applied.Longitude.ValueFloat64() != -104.9 ||
+		applied.Units.ValueString() != "imperial" || applied.SmartWatering.ValueBool() {
+		t.Errorf("got applied %+v", applied)
+	}
+	if len(settingsChanges(applied, desired)) != 0 {
+		t.Error("applied settings still differ from the desired ones")
+	}
+}
+
+func TestDeviceSettingsUpdate(t *testing.T) {
+	var puts []map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/session":
+			_, _ = w.Write([]byte(`{"orbit_session_token":"token"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/devices/abc":
+			var body map[string]map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			puts = append(puts, body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	data := &bhyveProviderData{
+		deviceID: "abc",
+		retry:    retryPolicy{maxElapsed: time.Second},
+		limiter:  rate.NewLimiter(rate.Inf, 1),
+	}
+	data.api = newAPIClient(server.URL, "me@example.com", "secret", data)
+	r := &deviceSettingsResource{data: data}
+	ctx := context.Background()
+
+	current := deviceSettingsResourceModel{Name: types.StringValue("Backyard"), Units: types.StringValue("imperial")}
+	desired := deviceSettingsResourceModel{Name: types.StringValue("Front yard"), Units: types.StringValue("imperial")}
+	if err := r.update(ctx, current, desired); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.update(ctx, desired, desired); err != nil {
+		t.Fatal(err)
+	}
+	if len(puts) != 1 || len(puts[0]["device"]) != 1 || puts[0]["device"]["name"] != "Front yard" {
+		t.Errorf("got requests %v, want one renaming the device", puts)
+	}
+
+	// A dry run records the request instead.
+	data.dryRun = &dryRunRecorder{}
+	if err := r.update(ctx, current, desired); err != nil {
+		t.Fatal(err)
+	}
+	if len(puts) != 1 {
+		t.Errorf("got %d requests in dry run mode", len(puts)-1)
+	}
+}
+
+func TestDeviceSettingsDryRunState(t *testing.T) {
+	// The data has no API client, so any request sent would panic.
+	r := &deviceSettingsResource{data: &bhyveProviderData{
+		deviceID: "abc",
+		retry:    retryPolicy{maxElapsed: time.Second},
+		limiter:  rate.NewLimiter(rate.Inf, 1),
+		dryRun:   &dryRunRecorder{},
+	}}
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	value := func(name string) tftypes.Value {
+		model := deviceSettingsResourceModel{
+			ID:            types.StringValue("abc"),
+			Name:          types.StringValue(name),
+			Timezone:      types.StringNull(),
+			Latitude:      types.Float64Null(),
+			Longitude:     types.Float64Null(),
+			Units:         types.StringNull(),
+			SmartWatering: types.BoolNull(),
+			Timeouts: timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
+				"create": types.StringType,
+				"read":   types.StringType,
+				"update": types.StringType,
+				"delete": types.StringType,
+			})},
+		}
+		raw := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+		if diags := raw.Set(ctx, model); diags.HasError() {
+			t.Fatalf("building value: %v", diags)
+		}
+		return raw.Raw
+	}
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: value("Backyard")}
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: value("Front yard")}
+	resp := resource.UpdateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: plan.Raw}}
+	r.Update(ctx, resource.UpdateRequest{State: state, Plan: plan}, &resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Device Settings Not Applied" {
+		t.Fatalf("got %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.Equal(state.Raw) {
+		t.Errorf("got state %s, want the prior state", resp.State.Raw)
+	}
+}
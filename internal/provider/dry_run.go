@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// deviceCommand is a call that changes a device, such as starting a zone.
+// Payload is the message it sends, which dry run mode records instead.
+type deviceCommand struct {
+	Name     string
+	DeviceID string
+	Method   string
+	Path     string
+	Payload  interface{}
+
+	send func() error
+}
+
+// startZoneCommand starts a manual run of station through the client. The
+// payload mirrors the change_mode message the client sends.
+func startZoneCommand(c deviceClient, deviceID string, station, minutes int) deviceCommand {
+	return deviceCommand{
+		Name:     "start zone",
+		DeviceID: deviceID,
+		Payload: map[string]interface{}{
+			"event":     "change_mode",
+			"mode":      "manual",
+			"device_id": deviceID,
+			"timestamp": time.Now().Format(time.RFC3339),
+			"stations": []map[string]interface{}{
+				{"station": station, "run_time": minutes},
+			},
+		},
+		send: func() error {
+			return c.StartZone(station, minutes)
+		},
+	}
+}
+
+// startStationsCommand starts a manual run of runTimes, one station after
+// the other, on any device of the account. The client only commands the
+// device it was made for, so the change_mode message is sent on the events
+// websocket, which must be subscribed to the device.
+func startStationsCommand(hub *eventHub, deviceID string, runTimes []runTimeModel) deviceCommand {
+	stations := make([]map[string]interface{}, 0, len(runTimes))
+	for _, run := range runTimes {
+		stations = append(stations, map[string]interface{}{"station": run.Station, "run_time": run.Minutes})
+	}
+	payload := map[string]interface{}{
+		"event":     "change_mode",
+		"mode":      "manual",
+		"device_id": deviceID,
+		"timestamp": time.Now().Format(time.RFC3339),
+		"stations":  stations,
+	}
+	return deviceCommand{
+		Name:     "start stations",
+		DeviceID: deviceID,
+		Payload:  payload,
+		send: func() error {
+			return hub.send(payload)
+		},
+	}
+}
+
+// stopStationsCommand ends the manual run of a device of the account with an
+// empty change_mode message on the events websocket, like
+// startStationsCommand.
+func stopStationsCommand(hub *eventHub, deviceID string) deviceCommand {
+	payload := map[string]interface{}{
+		"event":     "change_mode",
+		"mode":      "manual",
+		"device_id": deviceID,
+		"timestamp": time.Now().Format(time.RFC3339),
+		"stations":  []map[string]interface{}{},
+	}
+	return deviceCommand{
+		Name:     "stop stations",
+		DeviceID: deviceID,
+		Payload:  payload,
+		send: func() error {
+			return hub.send(payload)
+		},
+	}
+}
+
+// updateDeviceCommand changes the settings of a device through the REST API.
+// settings holds only the fields to change.
+func updateDeviceCommand(ctx context.Context, api *apiClient, deviceID string, settings map[string]interface{}) deviceCommand {
+	path := "/devices/" + url.PathEscape(deviceID)
+	payload := map[string]interface{}{"device": settings}
+	return deviceCommand{
+		Name:     "update device settings",
+		DeviceID: deviceID,
+		Method:   http.MethodPut,
+		Path:     path,
+		Payload:  payload,
+		send: func() error {
+			return api.do(ctx, http.MethodPut, path, payload, nil)
+		},
+	}
+}
+
+// send sends cmd to the device, or only records it in dry run mode.
+func (d *bhyveProviderData) send(ctx context.Context, cmd deviceCommand) error {
+	if d.dryRun != nil {
+		return d.dryRun.record(ctx, cmd)
+	}
+	// REST commands go through the API client, whose transport already
+	// throttles and retries them.
+	if cmd.Method != "" {
+		return cmd.send()
+	}
+	return d.call(ctx, cmd.send)
+}
+
+// dryRunRecorder logs the commands that dry run mode holds back, and appends
+// them to a JSON lines file when path is set.
+type dryRunRecorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// dryRunEntry is a line of the dry run file.
+type dryRunEntry struct {
+	Time     string      `json:"time"`
+	DeviceID string      `json:"device_id"`
+	Command  string      `json:"command"`
+	Method   string      `json:"method,omitempty"`
+	Path     string      `json:"path,omitempty"`
+	Payload  interface{} `json:"payload"`
+}
+
+func (r *dryRunRecorder) record(ctx context.Context, cmd deviceCommand) error {
+	line, err := json.Marshal(dryRunEntry{
+		Time:     time.Now().Format(time.RFC3339),
+		DeviceID: cmd.DeviceID,
+		Command:  cmd.Name,
+		Method:   cmd.Method,
+		Path:     cmd.Path,
+		Payload:  cmd.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, "Dry run, not sending command to the device", map[string]interface{}{
+		"command": cmd.Name,
+		"entry":   string(line),
+	})
+	if r.path == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
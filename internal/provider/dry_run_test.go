@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/time/rate"
+)
+
+func TestDryRun(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "commands.jsonl")
+	d := &bhyveProviderData{
+		deviceID: "abc",
+		retry:    retryPolicy{maxElapsed: time.Second},
+		limiter:  rate.NewLimiter(rate.Inf, 1),
+		dryRun:   &dryRunRecorder{path: log},
+	}
+	ctx := context.Background()
+
+	sent := false
+	cmd := startZoneCommand(nil, "abc", 3, 10)
+	cmd.send = func() error {
+		sent = true
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := d.send(ctx, cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sent {
+		t.Error("command was sent in dry run mode")
+	}
+
+	f, err := os.Open(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %s", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	payload, _ := entries[0]["payload"].(map[string]interface{})
+	if entries[0]["command"] != "start zone" || entries[0]["device_id"] != "abc" || payload["mode"] != "manual" {
+		t.Errorf("got entry %v", entries[0])
+	}
+
+	// Without dry run the command is sent.
+	d.dryRun = nil
+	if err := d.send(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+	if !sent {
+		t.Error("command was not sent")
+	}
+}
+
+func TestDryRunStartCycle(t *testing.T) {
+	// The client is nil, so a sync or start sent to it would panic.
+	r := &zoneResource{data: &bhyveProviderData{
+		deviceID:   "abc",
+		retry:      retryPolicy{maxElapsed: time.Second},
+		limiter:    rate.NewLimiter(rate.Inf, 1),
+		dryRun:     &dryRunRecorder{},
+		guardrails: &guardrails{},
+	}}
+	plan := zoneResourceModel{ID: types.Int64Value(3), Minutes: types.Int64Value(10)}
+	if _, err := r.startCycle(context.Background(), nil, plan, []zoneCycle{{Minutes: 10}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.data.guardrails.runs) != 0 {
+		t.Errorf("dry run counted toward the daily limit: %v", r.data.guardrails.runs)
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = EstimateUsageFunction{}
+)
+
+// Volume units accepted by the usage estimates. Flow rates are always given
+// in gallons per minute, as reported by the B-hyve app.
+const (
+	unitGallons = "gallons"
+	unitLiters  = "liters"
+
+	litersPerGallon = 3.785411784
+)
+
+var zoneFlowAttrTypes = map[string]attr.Type{
+	"station":   types.Int64Type,
+	"flow_rate": types.Float64Type,
+}
+
+var zoneUsageAttrTypes = map[string]attr.Type{
+	"station":   types.Int64Type,
+	"minutes":   types.Int64Type,
+	"per_run":   types.Float64Type,
+	"per_week":  types.Float64Type,
+	"per_month": types.Float64Type,
+}
+
+type zoneFlowModel struct {
+	Station  int64   `tfsdk:"station"`
+	FlowRate float64 `tfsdk:"flow_rate"`
+}
+
+type usageEstimateModel struct {
+	Unit     string           `tfsdk:"unit"`
+	PerRun   float64          `tfsdk:"per_run"`
+	PerWeek  float64          `tfsdk:"per_week"`
+	PerMonth float64          `tfsdk:"per_month"`
+	Zones    []zoneUsageModel `tfsdk:"zones"`
+}
+
+type zoneUsageModel struct {
+	Station  int64   `tfsdk:"station"`
+	Minutes  int64   `tfsdk:"minutes"`
+	PerRun   float64 `tfsdk:"per_run"`
+	PerWeek  float64 `tfsdk:"per_week"`
+	PerMonth float64 `tfsdk:"per_month"`
+}
+
+func NewEstimateUsageFunction() function.Function {
+	return EstimateUsageFunction{}
+}
+
+type EstimateUsageFunction struct{}
+
+func (r EstimateUsageFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "estimate_usage"
+}
+
+func (r EstimateUsageFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Estimate the water used by a program",
+		MarkdownDescription: "Multiplies each zone's flow rate by its runtime in the program and by how often the " +
+			"program runs. Returns per run, per week and per month volumes, in total and for every station.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "zones",
+				MarkdownDescription: "Zones with `station` and `flow_rate` in gallons per minute.",
+				ElementType:         types.ObjectType{AttrTypes: zoneFlowAttrTypes},
+			},
+			function.ObjectParameter{
+				Name:                "program",
+				MarkdownDescription: "Program in the same shape accepted by `check_restrictions`.",
+				AttributeTypes:      programAttrTypes,
+			},
+			function.StringParameter{
+				Name:                "unit",
+				MarkdownDescription: "Volume unit of the result, `gallons` or `liters`.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"unit":      types.StringType,
+				"per_run":   types.Float64Type,
+				"per_week":  types.Float64Type,
+				"per_month": types.Float64Type,
+				"zones":     types.ListType{ElemType: types.ObjectType{AttrTypes: zoneUsageAttrTypes}},
+			},
+		},
+	}
+}
+
+func (r EstimateUsageFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var zones []zoneFlowModel
+	var program programModel
+	var unit string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &zones, &program, &unit))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if err := program.validate(); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	estimate, err := estimateUsage(zones, program, unit)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, estimate))
+}
+
+// estimateUsage computes the water used by every station in the program.
+// The program must already be validated.
+func estimateUsage(zones []zoneFlowModel, program programModel, unit string) (usageEstimateModel, error) {
+	var factor float64
+	switch unit {
+	case unitGallons:
+		factor = 1
+	case unitLiters:
+		factor = litersPerGallon
+	default:
+		return usageEstimateModel{}, fmt.Errorf("unknown unit %q, expected %q or %q", unit, unitGallons, unitLiters)
+	}
+
+	flowRates := make(map[int64]float64, len(zones))
+	for _, z := range zones {
+		if z.FlowRate < 0 {
+			return usageEstimateModel{}, fmt.Errorf("station %d has a negative flow rate", z.Station)
+		}
+		flowRates[z.Station] = z.FlowRate
+	}
+
+	estimate := usageEstimateModel{
+		Unit:  unit,
+		Zones: []zoneUsageModel{},
+	}
+	perWeek, perMonth := program.runsPerWeek(), program.runsPerMonth()
+	for _, rt := range program.RunTimes {
+		flowRate, ok := flowRates[rt.Station]
+		if !ok {
+			return usageEstimateModel{}, fmt.Errorf("no flow rate given for station %d", rt.Station)
+		}
+		perRun := flowRate * float64(rt.Minutes) * factor
+		estimate.Zones = append(estimate.Zones, zoneUsageModel{
+			Station:  rt.Station,
+			Minutes:  rt.Minutes,
+			PerRun:   perRun,
+			PerWeek:  perRun * perWeek,
+			PerMonth: perRun * perMonth,
+		})
+		estimate.PerRun += perRun
+	}
+	estimate.PerWeek = estimate.PerRun * perWeek
+	estimate.PerMonth = estimate.PerRun * perMonth
+
+	return estimate, nil
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateUsage(t *testing.T) {
+	interval := int64(2)
+	program := programModel{
+		Frequency:  frequencyModel{Type: frequencyDays, Days: []string{"mon", "wed", "fri"}},
+		StartTimes: []string{"05:00", "19:00"},
+		RunTimes:   []runTimeModel{{Station: 1, Minutes: 10}, {Station: 3, Minutes: 5}},
+	}
+	zones := []zoneFlowModel{
+		{Station: 1, FlowRate: 2},
+		{Station: 2, FlowRate: 7},
+		{Station: 3, FlowRate: 1.5},
+	}
+
+	got, err := estimateUsage(zones, program, unitGallons)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertClose(t, "per_run", got.PerRun, 27.5)
+	assertClose(t, "per_week", got.PerWeek, 165)
+	assertClose(t, "per_month", got.PerMonth, 165*daysPerMonth/7)
+	if len(got.Zones) != 2 || got.Zones[1].Station != 3 {
+		t.Fatalf("unexpected zones %+v", got.Zones)
+	}
+	assertClose(t, "station 3 per_week", got.Zones[1].PerWeek, 45)
+
+	program.Frequency = frequencyModel{Type: frequencyInterval, Interval: &interval}
+	got, err = estimateUsage(zones, program, unitLiters)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertClose(t, "per_run", got.PerRun, 27.5*litersPerGallon)
+	assertClose(t, "per_week", got.PerWeek, 27.5*litersPerGallon*7)
+
+	if _, err := estimateUsage(zones[:1], program, unitGallons); err == nil {
+		t.Error("expected an error for a station without a flow rate")
+	}
+	if _, err := estimateUsage(zones, program, "acre-feet"); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,413 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// Event names sent by B-hyve devices that resources wait on.
+const (
+	eventWateringInProgress = "watering_in_progress_notification"
+	eventWateringComplete   = "watering_complete"
+	eventChangeMode         = "change_mode"
+	eventDeviceIdle         = "device_idle"
+)
+
+// Keepalive timings of the events websocket. B-hyve drops connections that
+// stay quiet for about half a minute.
+const (
+	eventPingInterval = 25 * time.Second
+	eventIdleTimeout  = 90 * time.Second
+
+	eventBufferSize = 64
+)
+
+// deviceEvent is a message received on the B-hyve events websocket.
+type deviceEvent struct {
+	Event    string
+	DeviceID string
+	// Station is the station the event is about, zero when it names none.
+	Station int64
+	// Raw holds every field of the message.
+	Raw map[string]interface{}
+}
+
+func parseDeviceEvent(msg []byte) (deviceEvent, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return deviceEvent{}, err
+	}
+
+	ev := deviceEvent{Raw: raw}
+	ev.Event, _ = raw["event"].(string)
+	ev.DeviceID, _ = raw["device_id"].(string)
+	for _, key := range []string{"current_station", "station"} {
+		if station, ok := raw[key].(float64); ok {
+			ev.Station = int64(station)
+			break
+		}
+	}
+	return ev, nil
+}
+
+// eventTime returns the time the device stamped on ev, or fallback when it
+// has none.
+func eventTime(ev deviceEvent, fallback time.Time) time.Time {
+	if stamp, ok := ev.Raw["timestamp"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, stamp); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
+
+// wateringStarted matches the start of watering on a station.
+func wateringStarted(station int64) func(deviceEvent) bool {
+	return func(ev deviceEvent) bool {
+		return ev.Event == eventWateringInProgress && (ev.Station == 0 || ev.Station == station)
+	}
+}
+
+// wateringComplete matches the end of watering on a station. B-hyve does not
+// always name the station in watering_complete, so an event without one
+// matches any station.
+func wateringComplete(station int64) func(deviceEvent) bool {
+	return func(ev deviceEvent) bool {
+		return (ev.Event == eventWateringComplete || ev.Event == eventDeviceIdle) &&
+			(ev.Station == 0 || ev.Station == station)
+	}
+}
+
+// eventHub keeps one connection to the B-hyve events websocket and fans the
+// events out to subscriptions. It connects when the first subscription is
+// made, reconnects with backoff when the connection drops, and disconnects
+// when the last subscription is closed.
+type eventHub struct {
+	url    string
+	token  func(context.Context) (string, error)
+	dialer *websocket.Dialer
+	retry  retryPolicy
+	// limiter is the provider's rate limiter, shared with every other call
+	// to the B-hyve API. Each message the hub sends waits for it.
+	limiter *rate.Limiter
+
+	pingInterval time.Duration
+	idleTimeout  time.Duration
+
+	mu      sync.Mutex
+	subs    map[*eventSubscription]struct{}
+	conn    *websocket.Conn
+	ready   chan struct{}
+	stop    chan struct{}
+	writeMu sync.Mutex
+}
+
+func newEventHub(url string, token func(context.Context) (string, error), retry retryPolicy, limiter *rate.Limiter) *eventHub {
+	return &eventHub{
+		url:          url,
+		token:        token,
+		dialer:       websocket.DefaultDialer,
+		retry:        retry,
+		limiter:      limiter,
+		pingInterval: eventPingInterval,
+		idleTimeout:  eventIdleTimeout,
+		subs:         make(map[*eventSubscription]struct{}),
+	}
+}
+
+// eventSubscription receives the events of one device.
+type eventSubscription struct {
+	hub      *eventHub
+	deviceID string
+	events   chan deviceEvent
+	once     sync.Once
+}
+
+// subscribe starts receiving events from the device. It returns once the hub
+// is connected, so a command sent afterwards cannot miss its events. Close
+// the subscription when done with it.
+func (h *eventHub) subscribe(ctx context.Context, deviceID string) (*eventSubscription, error) {
+	s := &eventSubscription{
+		hub:      h,
+		deviceID: deviceID,
+		events:   make(chan deviceEvent, eventBufferSize),
+	}
+
+	h.mu.Lock()
+	first := !h.watching(deviceID)
+	h.subs[s] = struct{}{}
+	if h.stop == nil {
+		h.stop = make(chan struct{})
+		h.ready = make(chan struct{})
+		go h.run(context.WithoutCancel(ctx), h.stop)
+	}
+	conn, ready := h.conn, h.ready
+	h.mu.Unlock()
+
+	// A connection made later subscribes to every watched device itself.
+	if conn != nil && first {
+		if err := h.subscribeDevice(ctx, conn, deviceID); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("subscribing to device %s: %w", deviceID, err)
+		}
+	}
+
+	select {
+	case <-ready:
+		return s, nil
+	case <-ctx.Done():
+		s.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// watching reports whether any subscription is for the device. h.mu must be
+// held.
+func (h *eventHub) watching(deviceID string) bool {
+	for s := range h.subs {
+		if s.deviceID == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the subscription. The hub disconnects when none remain.
+func (s *eventSubscription) Close() {
+	s.once.Do(func() {
+		h := s.hub
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subs, s)
+		if len(h.subs) == 0 && h.stop != nil {
+			close(h.stop)
+			h.stop = nil
+			if h.conn != nil {
+				h.conn.Close()
+				h.conn = nil
+			}
+		}
+	})
+}
+
+// waitFor returns the first event matching match, or an error when ctx ends.
+func (s *eventSubscription) waitFor(ctx context.Context, match func(deviceEvent) bool) (deviceEvent, error) {
+	for {
+		select {
+		case ev := <-s.events:
+			if match(ev) {
+				return ev, nil
+			}
+		case <-ctx.Done():
+			return deviceEvent{}, ctx.Err()
+		}
+	}
+}
+
+// run connects and reads events until stop is closed.
+func (h *eventHub) run(ctx context.Context, stop chan struct{}) {
+	for attempt := 0; ; attempt++ {
+		conn, token, err := h.dial(ctx)
+		if err == nil {
+			attempt = 0
+			h.mu.Lock()
+			// The hub may have been stopped, and even restarted, while
+			// connecting.
+			if h.stop != stop {
+				h.mu.Unlock()
+				conn.Close()
+				return
+			}
+			// The connection is published with the same lock held as the
+			// devices to subscribe are listed, so a device first watched
+			// after this sees the connection and subscribes itself.
+			h.conn = conn
+			devices := make(map[string]bool)
+			for s := range h.subs {
+				devices[s.deviceID] = true
+			}
+			ready := h.ready
+			h.mu.Unlock()
+
+			connected := false
+			err = h.subscribeDevices(ctx, conn, token, devices)
+			if err == nil {
+				tflog.Debug(ctx, "Events websocket connected")
+				connected = true
+				close(ready)
+				err = h.read(ctx, conn)
+			} else {
+				conn.Close()
+			}
+
+			h.mu.Lock()
+			if h.stop == stop {
+				h.conn = nil
+				// Subscriptions made since are still waiting on ready
+				// when the connection never got ready.
+				if connected {
+					h.ready = make(chan struct{})
+				}
+			}
+			h.mu.Unlock()
+		}
+
+		if isClosed(stop) {
+			return
+		}
+		wait := h.retry.backoff(attempt)
+		tflog.Warn(ctx, "Events websocket disconnected, reconnecting", map[string]interface{}{
+			"error": err.Error(),
+			"wait":  wait.String(),
+		})
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// dial opens a connection to the websocket and returns it with the session
+// token to subscribe it with.
+func (h *eventHub) dial(ctx context.Context) (*websocket.Conn, string, error) {
+	token, err := h.token(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, _, err := h.dialer.DialContext(ctx, h.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return conn, token, nil
+}
+
+// subscribeDevices subscribes a new connection to devices.
+func (h *eventHub) subscribeDevices(ctx context.Context, conn *websocket.Conn, token string, devices map[string]bool) error {
+	for deviceID := range devices {
+		if err := h.write(ctx, conn, appConnection(token, deviceID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *eventHub) subscribeDevice(ctx context.Context, conn *websocket.Conn, deviceID string) error {
+	token, err := h.token(ctx)
+	if err != nil {
+		return err
+	}
+	return h.write(ctx, conn, appConnection(token, deviceID))
+}
+
+// appConnection is the message that subscribes a connection to a device.
+func appConnection(token, deviceID string) map[string]string {
+	return map[string]string{
+		"event":               "app_connection",
+		"orbit_session_token": token,
+		"subscribe_device_id": deviceID,
+	}
+}
+
+// send writes msg on the current connection. Subscribe to the device msg is
+// for first, which also makes sure the hub is connected. It does not wait for
+// the rate limiter, as commands are sent through bhyveProviderData.send,
+// which already does.
+func (h *eventHub) send(msg interface{}) error {
+	h.mu.Lock()
+	conn := h.conn
+	h.mu.Unlock()
+	if conn == nil {
+		return errors.New("the events websocket is not connected")
+	}
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	return conn.WriteJSON(msg)
+}
+
+// write sends msg once the rate limiter allows it.
+func (h *eventHub) write(ctx context.Context, conn *websocket.Conn, msg interface{}) error {
+	if err := h.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	return conn.WriteJSON(msg)
+}
+
+// read dispatches events until the connection fails or stays idle for longer
+// than the idle timeout. Pings are sent meanwhile to keep it open.
+func (h *eventHub) read(ctx context.Context, conn *websocket.Conn) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(h.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := h.write(ctx, conn, map[string]string{"event": "ping"}); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(h.idleTimeout)); err != nil {
+			return err
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+
+		ev, err := parseDeviceEvent(msg)
+		if err != nil {
+			tflog.Debug(ctx, "Ignoring malformed event", map[string]interface{}{"error": err.Error()})
+			continue
+		}
+		h.dispatch(ctx, ev)
+	}
+}
+
+func (h *eventHub) dispatch(ctx context.Context, ev deviceEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		if ev.DeviceID != "" && ev.DeviceID != s.deviceID {
+			continue
+		}
+		select {
+		case s.events <- ev:
+		default:
+			tflog.Warn(ctx, "Dropping device event, subscriber is not keeping up", map[string]interface{}{
+				"event":     ev.Event,
+				"device_id": ev.DeviceID,
+			})
+		}
+	}
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+)
+
+// fakeEvents is an events websocket that hands each connection to the test.
+type fakeEvents struct {
+	server *httptest.Server
+	conns  chan *websocket.Conn
+}
+
+func newFakeEvents(t *testing.T) *fakeEvents {
+	f := &fakeEvents{conns: make(chan *websocket.Conn, 4)}
+	upgrader := websocket.Upgrader{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		f.conns <- conn
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeEvents) hub() *eventHub {
+	h := newEventHub(
+		"ws"+strings.TrimPrefix(f.server.URL, "http"),
+		func(context.Context) (string, error) { return "token", nil },
+		retryPolicy{maxRetries: 3, maxElapsed: time.Second},
+		rate.NewLimiter(rate.Inf, 1),
+	)
+	h.pingInterval = 10 * time.Millisecond
+	h.idleTimeout = time.Second
+	return h
+}
+
+// accept returns the next connection and the device it subscribed to.
+func (f *fakeEvents) accept(t *testing.T) (*websocket.Conn, string) {
+	t.Helper()
+	select {
+	case conn := <-f.conns:
+		for {
+			var msg map[string]string
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("reading subscription: %s", err)
+			}
+			if msg["event"] == "app_connection" {
+				if msg["orbit_session_token"] != "token" {
+					t.Errorf("subscribed with token %q", msg["orbit_session_token"])
+				}
+				return conn, msg["subscribe_device_id"]
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("hub did not connect")
+	}
+	return nil, ""
+}
+
+func TestEventHub(t *testing.T) {
+	f := newFakeEvents(t)
+	h := f.hub()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub, err := h.subscribe(ctx, "device-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, device := f.accept(t)
+	if device != "device-a" {
+		t.Errorf("subscribed to %q", device)
+	}
+
+	// Events for other devices and stations are skipped.
+	for _, msg := range []string{
+		`{"event":"watering_complete","device_id":"device-b","current_station":3}`,
+		`not json`,
+		`{"event":"watering_complete","device_id":"device-a","current_station":2}`,
+		`{"event":"watering_complete","device_id":"device-a","current_station":3}`,
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ev, err := sub.waitFor(ctx, wateringComplete(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.DeviceID != "device-a" || ev.Station != 3 {
+		t.Errorf("got event %+v", ev)
+	}
+
+	// The hub reconnects and subscribes again when the connection drops.
+	conn.Close()
+	conn, device = f.accept(t)
+	if device != "device-a" {
+		t.Errorf("resubscribed to %q", device)
+	}
+	if err := conn.WriteJSON(map[string]interface{}{"event": "device_idle", "device_id": "device-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.waitFor(ctx, wateringComplete(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the last subscription disconnects.
+	sub.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	h.mu.Lock()
+	stopped := h.stop == nil && h.conn == nil
+	h.mu.Unlock()
+	if !stopped {
+		t.Error("hub still running after the last subscription closed")
+	}
+}
+
+func TestEventHubSubscribeWhileConnecting(t *testing.T) {
+	f := newFakeEvents(t)
+	h := f.hub()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Hold back the writes of the first connection, so device-b is first
+	// watched while it is being subscribed to device-a.
+	h.writeMu.Lock()
+	subs := make(chan error, 2)
+	subscribe := func(deviceID string) {
+		sub, err := h.subscribe(ctx, deviceID)
+		if err == nil {
+			defer sub.Close()
+			_, err = sub.waitFor(ctx, func(deviceEvent) bool { return true })
+		}
+		subs <- err
+	}
+	go subscribe("device-a")
+	conn := <-f.conns
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		h.mu.Lock()
+		connected := h.conn != nil
+		h.mu.Unlock()
+		if connected {
+			break
+		}
+	}
+	go subscribe("device-b")
+	time.Sleep(20 * time.Millisecond)
+	h.writeMu.Unlock()
+
+	subscribed := map[string]bool{}
+	for len(subscribed) < 2 {
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]string
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("got subscriptions to %v: %s", subscribed, err)
+		}
+		if msg["event"] == "app_connection" {
+			subscribed[msg["subscribe_device_id"]] = true
+		}
+	}
+
+	// Both subscriptions receive events once the hub is connected.
+	if err := conn.WriteJSON(map[string]interface{}{"event": "device_idle"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-subs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestEventSubscriptionWaitForCanceled(t *testing.T) {
+	f := newFakeEvents(t)
+	h := f.hub()
+
+	sub, err := h.subscribe(context.Background(), "device-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	f.accept(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := sub.waitFor(ctx, wateringComplete(1)); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+}
+
+func TestParseDeviceEvent(t *testing.T) {
+	ev, err := parseDeviceEvent([]byte(`{"event":"watering_in_progress_notification","device_id":"abc","current_station":4,"run_time":10}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != eventWateringInProgress || ev.DeviceID != "abc" || ev.Station != 4 {
+		t.Errorf("got %+v", ev)
+	}
+	if ev.Raw["run_time"] != float64(10) {
+		t.Errorf("raw fields not kept: %v", ev.Raw)
+	}
+
+	if wateringComplete(4)(ev) {
+		t.Error("in progress event matched watering complete")
+	}
+	if !wateringComplete(4)(deviceEvent{Event: eventWateringComplete}) {
+		t.Error("watering complete without a station did not match")
+	}
+}
+
+func TestEventHubRateLimit(t *testing.T) {
+	f := newFakeEvents(t)
+	h := f.hub()
+	h.limiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub, err := h.subscribe(ctx, "device-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	conn, _ := f.accept(t)
+
+	// Pings are due every 10ms, but the limiter allows one every 100ms.
+	pings := 0
+	deadline := time.Now().Add(350 * time.Millisecond)
+	for {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]string
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
+		if msg["event"] == "ping" {
+			pings++
+		}
+	}
+	if pings == 0 || pings > 4 {
+		t.Errorf("got %d pings in 350ms, want them limited to one per 100ms", pings)
+	}
+}
@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Events of the device event log that are not also sent on the events
+// websocket.
+const (
+	eventLowBattery         = "low_battery"
+	eventDeviceDisconnected = "device_disconnected"
+	eventFlowSensorAlarm    = "flow_sensor_alarm"
+	eventHighFlowAlarm      = "high_flow_alarm"
+	eventNoFlowAlarm        = "no_flow_alarm"
+	eventFirmwareUpdate     = "firmware_update"
+)
+
+// Severities of event log entries, from least to most severe.
+const (
+	severityInfo     = "info"
+	severityWarning  = "warning"
+	severityCritical = "critical"
+)
+
+var severities = []string{severityInfo, severityWarning, severityCritical}
+
+// logEventKind is the type and severity the data source reports for a device
+// event.
+type logEventKind struct {
+	Type     string
+	Severity string
+}
+
+// logEventKinds classifies device events. Events not listed are reported
+// with type "other" and severity "info".
+var logEventKinds = map[string]logEventKind{
+	eventWateringInProgress: {"watering_started", severityInfo},
+	eventWateringComplete:   {"watering_complete", severityInfo},
+	eventRainDelay:          {"rain_delay", severityInfo},
+	eventLowBattery:         {"low_battery", severityWarning},
+	eventDeviceDisconnected: {"device_offline", severityWarning},
+	eventFlowSensorAlarm:    {"flow_sensor_alarm", severityCritical},
+	eventHighFlowAlarm:      {"flow_sensor_alarm", severityCritical},
+	eventNoFlowAlarm:        {"flow_sensor_alarm", severityCritical},
+	eventFirmwareUpdate:     {"firmware_update", severityInfo},
+}
+
+var logEventTypes = []string{
+	"watering_started", "watering_complete", "rain_delay", "low_battery",
+	"device_offline", "flow_sensor_alarm", "firmware_update", "other",
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &eventLogDataSource{}
+	_ datasource.DataSourceWithConfigure = &eventLogDataSource{}
+)
+
+// NewEventLogDataSource returns the data source reading the event log of the
+// device.
+func NewEventLogDataSource() datasource.DataSource {
+	return &eventLogDataSource{}
+}
+
+// eventLogDataSource is the data source implementation.
+type eventLogDataSource struct {
+	data *bhyveProviderData
+}
+
+type eventLogDataSourceModel struct {
+	Since       types.String    `tfsdk:"since"`
+	Until       types.String    `tfsdk:"until"`
+	Types       []types.String  `tfsdk:"types"`
+	MinSeverity types.String    `tfsdk:"min_severity"`
+	Events      []logEventModel `tfsdk:"events"`
+}
+
+type logEventModel struct {
+	Time     types.String `tfsdk:"time"`
+	Type     types.String `tfsdk:"type"`
+	Event    types.String `tfsdk:"event"`
+	Severity types.String `tfsdk:"severity"`
+	Station  types.Int64  `tfsdk:"station"`
+	Message  types.String `tfsdk:"message"`
+}
+
+// Metadata returns the data source type name.
+func (d *eventLogDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_event_log"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *eventLogDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *eventLogDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the event log of the device, such as runs, rain delays, alarms and firmware updates, optionally filtered.",
+		Attributes: map[string]schema.Attribute{
+			"since": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list events at or after this RFC 3339 time.",
+				Validators:  []validator.String{timestampValidator{}},
+			},
+			"until": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list events before this RFC 3339 time.",
+				Validators:  []validator.String{timestampValidator{}},
+			},
+			"types": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Only list events of these types.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(logEventTypes...)),
+				},
+			},
+			"min_severity": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list events at least this severe: \"info\", \"warning\" or \"critical\".",
+				Validators:  []validator.String{stringvalidator.OneOf(severities...)},
+			},
+			"events": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Events of the device, newest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"time": schema.StringAttribute{
+							Computed:    true,
+							Description: "When the event happened, in RFC 3339.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the event, such as \"watering_started\" or \"flow_sensor_alarm\", or \"other\".",
+						},
+						"event": schema.StringAttribute{
+							Computed:    true,
+							Description: "Event name as reported by the device.",
+						},
+						"severity": schema.StringAttribute{
+							Computed:    true,
+							Description: "One of \"info\", \"warning\" or \"critical\".",
+						},
+						"station": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Station the event is about, null for events about the whole device.",
+						},
+						"message": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the event, null when the device gives none.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *eventLogDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state eventLogDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	events, err := d.data.api.eventLog(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Event Log", "Could not read the device event log: "+err.Error())
+		return
+	}
+
+	// The values are checked by their validators.
+	var filter logFilter
+	if !state.Since.IsNull() {
+		filter.since, _ = time.Parse(time.RFC3339, state.Since.ValueString())
+	}
+	if !state.Until.IsNull() {
+		filter.until, _ = time.Parse(time.RFC3339, state.Until.ValueString())
+	}
+	for _, t := range state.Types {
+		filter.types = append(filter.types, t.ValueString())
+	}
+	filter.minSeverity = state.MinSeverity.ValueString()
+
+	state.Events = []logEventModel{}
+	for _, ev := range filter.apply(events) {
+		state.Events = append(state.Events, logEvent(ev))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// logFilter selects event log entries. Unset fields select every entry.
+type logFilter struct {
+	since, until time.Time
+	types        []string
+	minSeverity  string
+}
+
+// loggedEvent is an event log entry with its parsed time and kind.
+type loggedEvent struct {
+	apiLogEvent
+	at   time.Time
+	kind logEventKind
+}
+
+func (f logFilter) apply(events []apiLogEvent) []loggedEvent {
+	var selected []loggedEvent
+	for _, ev := range events {
+		at, err := time.Parse(time.RFC3339, ev.Timestamp)
+		if err != nil {
+			continue
+		}
+		if !f.since.IsZero() && at.Before(f.since) || !f.until.IsZero() && !at.Before(f.until) {
+			continue
+		}
+		kind := logEventKindOf(ev.Event)
+		if len(f.types) > 0 && !slices.Contains(f.types, kind.Type) {
+			continue
+		}
+		if f.minSeverity != "" && severityRank(kind.Severity) < severityRank(f.minSeverity) {
+			continue
+		}
+		selected = append(selected, loggedEvent{apiLogEvent: ev, at: at, kind: kind})
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].at.After(selected[j].at)
+	})
+	return selected
+}
+
+// logEventKindOf returns the type and severity of a device event.
+func logEventKindOf(event string) logEventKind {
+	if kind, ok := logEventKinds[event]; ok {
+		return kind
+	}
+	return logEventKind{"other", severityInfo}
+}
+
+// severityRank orders severities from least to most severe.
+func severityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return 0
+}
+
+// logEvent maps an event log entry to its data source model.
+func logEvent(ev loggedEvent) logEventModel {
+	return logEventModel{
+		Time:     types.StringValue(ev.at.Format(time.RFC3339)),
+		Type:     types.StringValue(ev.kind.Type),
+		Event:    types.StringValue(ev.Event),
+		Severity: types.StringValue(ev.kind.Severity),
+		Station:  types.Int64PointerValue(ev.Station),
+		Message:  optionalString(ev.Message),
+	}
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEventLogDataSource(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/event_logs": `[
+			{"event":"watering_in_progress_notification","timestamp":"2024-06-01T05:00:00Z","station":1},
+			{"event":"low_battery","timestamp":"2024-06-02T08:00:00Z","message":"Battery at 10%"},
+			{"event":"high_flow_alarm","timestamp":"2024-06-03T05:10:00Z","station":2},
+			{"event":"watering_complete","timestamp":"2024-06-03T05:20:00Z","station":2},
+			{"event":"clock_sync","timestamp":"2024-06-04T00:00:00Z"},
+			{"event":"firmware_update","timestamp":"not a time"}
+		]`,
+	})
+	ds := &eventLogDataSource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+
+	tests := []struct {
+		name   string
+		config eventLogDataSourceModel
+		want   []string
+	}{
+		{name: "all", want: []string{"other", "watering_complete", "flow_sensor_alarm", "low_battery", "watering_started"}},
+		{
+			name:   "time range",
+			config: eventLogDataSourceModel{Since: types.StringValue("2024-06-02T08:00:00Z"), Until: types.StringValue("2024-06-03T05:20:00Z")},
+			want:   []string{"flow_sensor_alarm", "low_battery"},
+		},
+		{
+			name:   "types",
+			config: eventLogDataSourceModel{Types: []types.String{types.StringValue("watering_started"), types.StringValue("watering_complete")}},
+			want:   []string{"watering_complete", "watering_started"},
+		},
+		{name: "severity", config: eventLogDataSourceModel{MinSeverity: types.StringValue("warning")}, want: []string{"flow_sensor_alarm", "low_battery"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got eventLogDataSourceModel
+			readDataSource(t, ds, &tt.config, &got)
+
+			var kinds []string
+			for _, ev := range got.Events {
+				kinds = append(kinds, ev.Type.ValueString())
+			}
+			if len(kinds) != len(tt.want) {
+				t.Fatalf("got types %v, want %v", kinds, tt.want)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.want[i] {
+					t.Fatalf("got types %v, want %v", kinds, tt.want)
+				}
+			}
+		})
+	}
+
+	var got eventLogDataSourceModel
+	readDataSource(t, ds, &eventLogDataSourceModel{MinSeverity: types.StringValue("warning")}, &got)
+	alarm, battery := got.Events[0], got.Events[1]
+	if alarm.Severity.ValueString() != "critical" || alarm.Event.ValueString() != "high_flow_alarm" || alarm.Station.ValueInt64() != 2 || !alarm.Message.IsNull() {
+		t.Errorf("got %+v", alarm)
+	}
+	if battery.Time.ValueString() != "2024-06-02T08:00:00Z" || !battery.Station.IsNull() || battery.Message.ValueString() != "Battery at 10%" {
+		t.Errorf("got %+v", battery)
+	}
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TestExampleDays parses the examples and checks that every literal list of
+// days in them names days the provider functions accept.
+func TestExampleDays(t *testing.T) {
+	err := filepath.WalkDir("../../examples", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(name, ".tf") {
+			return err
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		file, diags := hclsyntax.ParseConfig(src, name, hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Errorf("%s: %s", name, diags)
+			return nil
+		}
+
+		diags = hclsyntax.VisitAll(file.Body.(*hclsyntax.Body), func(node hclsyntax.Node) hcl.Diagnostics {
+			object, ok := node.(*hclsyntax.ObjectConsExpr)
+			if !ok {
+				return nil
+			}
+			for _, item := range object.Items {
+				key, _ := item.KeyExpr.Value(nil)
+				if key.Type() != cty.String || !strings.HasSuffix(key.AsString(), "days") {
+					continue
+				}
+				days, ok := item.ValueExpr.(*hclsyntax.TupleConsExpr)
+				if !ok {
+					continue
+				}
+				for _, expr := range days.Exprs {
+					day, diags := expr.Value(nil)
+					if diags.HasErrors() || day.Type() != cty.String {
+						t.Errorf("%s: not a day name", expr.Range())
+						continue
+					}
+					if _, err := parseWeekday(day.AsString()); err != nil {
+						t.Errorf("%s: %s", expr.Range(), err)
+					}
+				}
+			}
+			return nil
+		})
+		if diags.HasErrors() {
+			t.Errorf("%s: %s", name, diags)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// guardrails are provider policies that refuse a run before any command is
+// sent. Times are taken in the time zone of the machine running Terraform.
+type guardrails struct {
+	quietHours []timeWindowModel
+	// maxZoneMinutes and maxDailyMinutes are zero when not limited.
+	maxZoneMinutes  int64
+	maxDailyMinutes int64
+
+	// runs are those started by this provider instance, on any device of
+	// the account, which the device history may not list yet.
+	mu   sync.Mutex
+	runs []guardedRun
+}
+
+type guardedRun struct {
+	deviceID string
+	station  int64
+	start    time.Time
+	minutes  int64
+}
+
+// policyError is a run refused by a guardrail. Summary and Detail are meant
+// for a diagnostic.
+type policyError struct {
+	Summary string
+	Detail  string
+}
+
+func (e *policyError) Error() string {
+	return e.Detail
+}
+
+// checkMinutes refuses runs longer than max_zone_minutes.
+func (g *guardrails) checkMinutes(station, minutes int64) *policyError {
+	if g == nil || g.maxZoneMinutes == 0 || minutes <= g.maxZoneMinutes {
+		return nil
+	}
+	return &policyError{
+		Summary: "Run Blocked by max_zone_minutes",
+		Detail: fmt.Sprintf("Station %d would run for %d minutes, more than the %d minutes allowed by the provider's max_zone_minutes policy.",
+			station, minutes, g.maxZoneMinutes),
+	}
+}
+
+// checkQuietHours refuses runs that would water during quiet_hours.
+func (g *guardrails) checkQuietHours(station, minutes int64, start time.Time) *policyError {
+	if g == nil {
+		return nil
+	}
+	clock := start.Hour()*60 + start.Minute()
+	for _, w := range g.quietHours {
+		if overlapsWindow(clock, int(minutes), w) {
+			return &policyError{
+				Summary: "Run Blocked by quiet_hours",
+				Detail: fmt.Sprintf("Station %d would run from %s to %s, overlapping the quiet hours %s-%s set by the provider's quiet_hours policy.",
+					station, formatClock(clock), formatClock(clock+int(minutes)), w.Start, w.End),
+			}
+		}
+	}
+	return nil
+}
+
+// checkDaily refuses runs that would take the device's watering on the day of
+// start past max_daily_minutes_per_device. events is the device history.
+func (g *guardrails) checkDaily(deviceID string, station, minutes int64, start time.Time, events []apiWateringEvent) *policyError {
+	if g == nil || g.maxDailyMinutes == 0 {
+		return nil
+	}
+	used := g.minutesOn(deviceID, start, events)
+	if used+float64(minutes) <= float64(g.maxDailyMinutes) {
+		return nil
+	}
+	return &policyError{
+		Summary: "Run Blocked by max_daily_minutes_per_device",
+		Detail: fmt.Sprintf("Station %d would run for %d minutes, but the device has already watered %.0f minutes today. "+
+			"That is more than the %d minutes a day allowed by the provider's max_daily_minutes_per_device policy.",
+			station, minutes, used, g.maxDailyMinutes),
+	}
+}
+
+// minutesOn returns the minutes the device watered on the day of t, from its
+// history and the runs this provider instance started on it that the history
+// does not list.
+func (g *guardrails) minutesOn(deviceID string, t time.Time, events []apiWateringEvent) float64 {
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	onDay := func(at time.Time) bool {
+		return !at.Before(dayStart) && at.Before(dayEnd)
+	}
+
+	var used float64
+	var history []guardedRun
+	for _, ev := range events {
+		for _, irrigation := range ev.Irrigation {
+			start := irrigation.StartTime
+			if start == "" {
+				start = ev.StartTime
+			}
+			at, err := time.Parse(time.RFC3339, start)
+			if err != nil || !onDay(at) {
+				continue
+			}
+			used += irrigation.RunTime
+			history = append(history, guardedRun{station: irrigation.Station, start: at})
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, run := range g.runs {
+		if run.deviceID == deviceID && onDay(run.start) && !listed(run, history) {
+			used += float64(run.minutes)
+		}
+	}
+	return used
+}
+
+// listed reports whether history has a run of the same station starting
+// within a couple of minutes of run.
+func listed(run guardedRun, history []guardedRun) bool {
+	for _, h := range history {
+		if h.station == run.station && h.start.Sub(run.start).Abs() <= 2*time.Minute {
+			return true
+		}
+	}
+	return false
+}
+
+// check applies every guardrail to a run starting now. The device history is
+// only fetched when a daily limit is set.
+func (g *guardrails) check(ctx context.Context, api *apiClient, deviceID string, station, minutes int64, now time.Time) error {
+	if g == nil {
+		return nil
+	}
+	if policy := g.checkMinutes(station, minutes); policy != nil {
+		return policy
+	}
+	if policy := g.checkQuietHours(station, minutes, now); policy != nil {
+		return policy
+	}
+	if g.maxDailyMinutes == 0 {
+		return nil
+	}
+	events, err := api.wateringEvents(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("reading watering history for max_daily_minutes_per_device: %w", err)
+	}
+	if policy := g.checkDaily(deviceID, station, minutes, now, events); policy != nil {
+		return policy
+	}
+	return nil
+}
+
+// checkSequence applies every guardrail to stations of a device that run one
+// after the other from now, as the zones of a group do. The device history
+// is only fetched when a daily limit is set.
+func (g *guardrails) checkSequence(ctx context.Context, api *apiClient, deviceID string, runTimes []runTimeModel, now time.Time) error {
+	if g == nil {
+		return nil
+	}
+	var total int64
+	for _, run := range runTimes {
+		if policy := g.checkMinutes(run.Station, run.Minutes); policy != nil {
+			return policy
+		}
+		start := now.Add(time.Duration(total) * time.Minute)
+		if policy := g.checkQuietHours(run.Station, run.Minutes, start); policy != nil {
+			return policy
+		}
+		total += run.Minutes
+	}
+	if g.maxDailyMinutes == 0 {
+		return nil
+	}
+	events, err := api.wateringEvents(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("reading watering history for max_daily_minutes_per_device: %w", err)
+	}
+	if used := g.minutesOn(deviceID, now, events); used+float64(total) > float64(g.maxDailyMinutes) {
+		return &policyError{
+			Summary: "Run Blocked by max_daily_minutes_per_device",
+			Detail: fmt.Sprintf("The stations of device %s would run for %d minutes, but the device has already watered %.0f minutes today. "+
+				"That is more than the %d minutes a day allowed by the provider's max_daily_minutes_per_device policy.",
+				deviceID, total, used, g.maxDailyMinutes),
+		}
+	}
+	return nil
+}
+
+// started records a run so later checks count it before the history does.
+func (g *guardrails) started(deviceID string, station, minutes int64, start time.Time) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.runs = append(g.runs, guardedRun{deviceID: deviceID, station: station, start: start, minutes: minutes})
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuardrails(t *testing.T) {
+	g := &guardrails{
+		quietHours:      []timeWindowModel{{Start: "22:00", End: "06:00"}},
+		maxZoneMinutes:  30,
+		maxDailyMinutes: 60,
+	}
+	at := func(clock string) time.Time {
+		t, _ := time.Parse(time.RFC3339, "2024-06-03T"+clock+":00Z")
+		return t
+	}
+	history := []apiWateringEvent{
+		{StartTime: "2024-06-03T07:00:00.000Z", Irrigation: []apiIrrigation{{Station: 1, RunTime: 20}, {Station: 2, RunTime: 15}}},
+		// The day before does not count.
+		{StartTime: "2024-06-02T07:00:00.000Z", Irrigation: []apiIrrigation{{Station: 1, RunTime: 50}}},
+	}
+
+	tests := []struct {
+		name    string
+		minutes int64
+		start   time.Time
+		want    string
+	}{
+		{name: "allowed", minutes: 20, start: at("12:00")},
+		{name: "too long", minutes: 45, start: at("12:00"), want: "max_zone_minutes"},
+		{name: "in quiet hours", minutes: 10, start: at("23:00"), want: "quiet_hours"},
+		{name: "runs into quiet hours", minutes: 20, start: at("21:50"), want: "quiet_hours"},
+		{name: "over the daily limit", minutes: 30, start: at("12:00"), want: "max_daily_minutes_per_device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := g.checkMinutes(3, tt.minutes)
+			if policy == nil {
+				policy = g.checkQuietHours(3, tt.minutes, tt.start)
+			}
+			if policy == nil {
+				policy = g.checkDaily("abc", 3, tt.minutes, tt.start, history)
+			}
+			switch {
+			case tt.want == "" && policy != nil:
+				t.Errorf("unexpected refusal: %s", policy.Detail)
+			case tt.want != "" && (policy == nil || !strings.Contains(policy.Summary, tt.want)):
+				t.Errorf("got %v, want a refusal by %s", policy, tt.want)
+			}
+		})
+	}
+
+	// Runs started by the provider count until the history lists them.
+	g.started("abc", 3, 20, at("12:00"))
+	if policy := g.checkDaily("abc", 4, 10, at("13:00"), history); policy == nil {
+		t.Error("run started by the provider was not counted")
+	}
+	if policy := g.checkDaily("def", 4, 50, at("13:00"), nil); policy != nil {
+		t.Errorf("run started on another device was counted: %s", policy.Detail)
+	}
+	listed := append(history, apiWateringEvent{
+		StartTime:  "2024-06-03T12:01:00.000Z",
+		Irrigation: []apiIrrigation{{Station: 3, RunTime: 20}},
+	})
+	if used := g.minutesOn("abc", at("13:00"), listed); used != 55 {
+		t.Errorf("counted %v minutes, want 55", used)
+	}
+}
+
+func TestGuardrailsCheck(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/watering_events/abc": `[{"start_time":"2024-06-03T07:00:00.000Z","irrigation":[{"station":1,"run_time":50}]}]`,
+	})
+	now, _ := time.Parse(time.RFC3339, "2024-06-03T12:00:00Z")
+	ctx := context.Background()
+
+	var policy *policyError
+	g := &guardrails{maxDailyMinutes: 60}
+	if err := g.check(ctx, api, "abc", 2, 20, now); !errors.As(err, &policy) {
+		t.Errorf("got %v, want a policy error", err)
+	}
+	if err := g.check(ctx, api, "abc", 2, 10, now); err != nil {
+		t.Errorf("got %v, want the run allowed", err)
+	}
+
+	// The stations of a group count together, and each later one starts
+	// when the one before it ends.
+	if err := g.checkSequence(ctx, api, "abc", []runTimeModel{{Station: 1, Minutes: 5}, {Station: 2, Minutes: 10}}, now); !errors.As(err, &policy) {
+		t.Errorf("got %v, want a policy error", err)
+	}
+	if err := g.checkSequence(ctx, api, "abc", []runTimeModel{{Station: 1, Minutes: 5}, {Station: 2, Minutes: 5}}, now); err != nil {
+		t.Errorf("got %v, want the run allowed", err)
+	}
+	quiet := &guardrails{quietHours: []timeWindowModel{{Start: "13:00", End: "14:00"}}}
+	err := quiet.checkSequence(ctx, nil, "abc", []runTimeModel{{Station: 1, Minutes: 50}, {Station: 2, Minutes: 20}}, now)
+	if !errors.As(err, &policy) || !strings.Contains(policy.Detail, "Station 2") {
+		t.Errorf("got %v, want station 2 refused by quiet_hours", err)
+	}
+
+	// Without guardrails nothing is refused.
+	var none *guardrails
+	if err := none.check(ctx, nil, "abc", 2, 600, now); err != nil {
+		t.Errorf("got %v", err)
+	}
+}
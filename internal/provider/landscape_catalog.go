@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+)
+
+// landscapeOptionsJSON is the catalog of landscape options shipped with the
+// provider. Bump its version when changing it.
+//
+//go:embed landscape_options.json
+var landscapeOptionsJSON []byte
+
+// landscapeCatalog holds the values B-hyve accepts for the landscape settings
+// of a zone. What the coefficient of an option means depends on its catalog:
+//
+//   - crop types: crop coefficient, the fraction of reference
+//     evapotranspiration the plants use
+//   - soil types: infiltration rate in inches per hour
+//   - nozzle types: precipitation rate in inches per hour
+//   - sprinkler types: application efficiency
+//   - slope types: fraction of the soil infiltration rate left on the slope
+//   - exposure types: fraction of full sun evapotranspiration
+type landscapeCatalog struct {
+	Version        string            `json:"version"`
+	CropTypes      []landscapeOption `json:"crop_types"`
+	SoilTypes      []landscapeOption `json:"soil_types"`
+	NozzleTypes    []landscapeOption `json:"nozzle_types"`
+	SprinklerTypes []landscapeOption `json:"sprinkler_types"`
+	SlopeTypes     []landscapeOption `json:"slope_types"`
+	ExposureTypes  []landscapeOption `json:"exposure_types"`
+}
+
+// landscapeOption is a value of a landscape setting.
+type landscapeOption struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Coefficient float64 `json:"coefficient"`
+}
+
+// embeddedLandscapeCatalog returns the catalog shipped with the provider.
+func embeddedLandscapeCatalog() landscapeCatalog {
+	var catalog landscapeCatalog
+	if err := json.Unmarshal(landscapeOptionsJSON, &catalog); err != nil {
+		panic("parsing embedded landscape_options.json: " + err.Error())
+	}
+	return catalog
+}
+
+// landscapeOptions returns the current catalog of landscape options from the
+// B-hyve API.
+func (a *apiClient) landscapeOptions(ctx context.Context) (*landscapeCatalog, error) {
+	var catalog landscapeCatalog
+	if err := a.do(ctx, http.MethodGet, "/landscape_options", nil, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
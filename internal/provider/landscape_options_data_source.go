@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Sources of the landscape options.
+const (
+	landscapeSourceEmbedded = "embedded"
+	landscapeSourceAPI      = "api"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &landscapeOptionsDataSource{}
+	_ datasource.DataSourceWithConfigure = &landscapeOptionsDataSource{}
+)
+
+// NewLandscapeOptionsDataSource returns the data source listing the values
+// of the zone landscape settings.
+func NewLandscapeOptionsDataSource() datasource.DataSource {
+	return &landscapeOptionsDataSource{}
+}
+
+// landscapeOptionsDataSource is the data source implementation.
+type landscapeOptionsDataSource struct {
+	data *bhyveProviderData
+}
+
+type landscapeOptionsDataSourceModel struct {
+	Refresh        types.Bool             `tfsdk:"refresh"`
+	Version        types.String           `tfsdk:"version"`
+	Source         types.String           `tfsdk:"source"`
+	CropTypes      []landscapeOptionModel `tfsdk:"crop_types"`
+	SoilTypes      []landscapeOptionModel `tfsdk:"soil_types"`
+	NozzleTypes    []landscapeOptionModel `tfsdk:"nozzle_types"`
+	SprinklerTypes []landscapeOptionModel `tfsdk:"sprinkler_types"`
+	SlopeTypes     []landscapeOptionModel `tfsdk:"slope_types"`
+	ExposureTypes  []landscapeOptionModel `tfsdk:"exposure_types"`
+}
+
+type landscapeOptionModel struct {
+	ID          types.String  `tfsdk:"id"`
+	Name        types.String  `tfsdk:"name"`
+	Coefficient types.Float64 `tfsdk:"coefficient"`
+}
+
+// Metadata returns the data source type name.
+func (d *landscapeOptionsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_landscape_options"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *landscapeOptionsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *landscapeOptionsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	catalog := func(description string) schema.ListNestedAttribute {
+		return schema.ListNestedAttribute{
+			Computed:    true,
+			Description: description,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.StringAttribute{
+						Computed:    true,
+						Description: "Value of the setting, as reported on zones.",
+					},
+					"name": schema.StringAttribute{
+						Computed:    true,
+						Description: "Name shown in the B-hyve app.",
+					},
+					"coefficient": schema.Float64Attribute{
+						Computed:    true,
+						Description: "Default coefficient of the option, as described on its catalog.",
+					},
+				},
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Lists the values B-hyve accepts for the landscape settings of a zone, with their names and " +
+			"default coefficients. The list ships with the provider and can be refreshed from the B-hyve API.",
+		Attributes: map[string]schema.Attribute{
+			"refresh": schema.BoolAttribute{
+				Optional: true,
+				Description: "Read the options from the B-hyve API instead of the list shipped with the provider. " +
+					"When the API cannot be read, the shipped list is used with a warning.",
+			},
+			"version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Version of the options.",
+			},
+			"source": schema.StringAttribute{
+				Computed:    true,
+				Description: "Where the options were read from, \"embedded\" or \"api\".",
+			},
+			"crop_types":      catalog("Plants. The coefficient is the crop coefficient, the fraction of reference evapotranspiration they use."),
+			"soil_types":      catalog("Soils. The coefficient is the infiltration rate in inches per hour."),
+			"nozzle_types":    catalog("Nozzles. The coefficient is the precipitation rate in inches per hour."),
+			"sprinkler_types": catalog("Sprinklers. The coefficient is the application efficiency."),
+			"slope_types":     catalog("Slopes. The coefficient is the fraction of the soil infiltration rate left on the slope."),
+			"exposure_types":  catalog("Sun exposures. The coefficient is the fraction of full sun evapotranspiration."),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *landscapeOptionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state landscapeOptionsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalog, source := embeddedLandscapeCatalog(), landscapeSourceEmbedded
+	if state.Refresh.ValueBool() {
+		fresh, err := d.data.api.landscapeOptions(ctx)
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Using Shipped Landscape Options",
+				"Could not read the landscape options from the B-hyve API, using those shipped with the provider: "+err.Error(),
+			)
+		} else {
+			catalog, source = *fresh, landscapeSourceAPI
+		}
+	}
+
+	state.Version = types.StringValue(catalog.Version)
+	state.Source = types.StringValue(source)
+	state.CropTypes = landscapeOptionModels(catalog.CropTypes)
+	state.SoilTypes = landscapeOptionModels(catalog.SoilTypes)
+	state.NozzleTypes = landscapeOptionModels(catalog.NozzleTypes)
+	state.SprinklerTypes = landscapeOptionModels(catalog.SprinklerTypes)
+	state.SlopeTypes = landscapeOptionModels(catalog.SlopeTypes)
+	state.ExposureTypes = landscapeOptionModels(catalog.ExposureTypes)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func landscapeOptionModels(options []landscapeOption) []landscapeOptionModel {
+	models := []landscapeOptionModel{}
+	for _, o := range options {
+		models = append(models, landscapeOptionModel{
+			ID:          types.StringValue(o.ID),
+			Name:        types.StringValue(o.Name),
+			Coefficient: types.Float64Value(o.Coefficient),
+		})
+	}
+	return models
+}
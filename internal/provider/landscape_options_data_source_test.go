@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEmbeddedLandscapeCatalog(t *testing.T) {
+	catalog := embeddedLandscapeCatalog()
+	if catalog.Version == "" {
+		t.Error("embedded catalog has no version")
+	}
+	for name, options := range map[string][]landscapeOption{
+		"crop_types":      catalog.CropTypes,
+		"soil_types":      catalog.SoilTypes,
+		"nozzle_types":    catalog.NozzleTypes,
+		"sprinkler_types": catalog.SprinklerTypes,
+		"slope_types":     catalog.SlopeTypes,
+		"exposure_types":  catalog.ExposureTypes,
+	} {
+		if len(options) == 0 {
+			t.Errorf("%s is empty", name)
+		}
+		seen := map[string]bool{}
+		for _, o := range options {
+			if o.ID == "" || o.Name == "" || o.Coefficient <= 0 || seen[o.ID] {
+				t.Errorf("%s has invalid option %+v", name, o)
+			}
+			seen[o.ID] = true
+		}
+	}
+}
+
+func TestLandscapeOptionsDataSource(t *testing.T) {
+	embedded := embeddedLandscapeCatalog()
+
+	var got landscapeOptionsDataSourceModel
+	ds := &landscapeOptionsDataSource{data: &bhyveProviderData{api: newFakeAPI(t, nil)}}
+	readDataSource(t, ds, &landscapeOptionsDataSourceModel{}, &got)
+	if got.Source.ValueString() != "embedded" || got.Version.ValueString() != embedded.Version || len(got.SoilTypes) != len(embedded.SoilTypes) {
+		t.Errorf("got %+v", got)
+	}
+
+	// The API is not found, so the shipped options are used.
+	got = landscapeOptionsDataSourceModel{}
+	readDataSource(t, ds, &landscapeOptionsDataSourceModel{Refresh: types.BoolValue(true)}, &got)
+	if got.Source.ValueString() != "embedded" {
+		t.Errorf("got source %v", got.Source)
+	}
+
+	ds = &landscapeOptionsDataSource{data: &bhyveProviderData{api: newFakeAPI(t, map[string]string{
+		"/landscape_options": `{"version":"2099-01-01","soil_types":[{"id":"peat","name":"Peat","coefficient":0.3}]}`,
+	})}}
+	got = landscapeOptionsDataSourceModel{}
+	readDataSource(t, ds, &landscapeOptionsDataSourceModel{Refresh: types.BoolValue(true)}, &got)
+	if got.Source.ValueString() != "api" || got.Version.ValueString() != "2099-01-01" || len(got.SoilTypes) != 1 || got.SoilTypes[0].ID.ValueString() != "peat" || len(got.CropTypes) != 0 {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &programDataSource{}
+	_ datasource.DataSourceWithConfigure = &programDataSource{}
+)
+
+// NewProgramDataSource returns the data source reading one program of the
+// device.
+func NewProgramDataSource() datasource.DataSource {
+	return &programDataSource{}
+}
+
+// programDataSource is the data source implementation.
+type programDataSource struct {
+	data *bhyveProviderData
+}
+
+// programDataModel is a program as read from the device. Its name, frequency,
+// start_times and run_times have the shape of the program object taken by the
+// provider functions.
+type programDataModel struct {
+	ID         types.String   `tfsdk:"id"`
+	Program    types.String   `tfsdk:"program"`
+	Name       types.String   `tfsdk:"name"`
+	Enabled    types.Bool     `tfsdk:"enabled"`
+	Frequency  frequencyModel `tfsdk:"frequency"`
+	StartTimes []string       `tfsdk:"start_times"`
+	RunTimes   []runTimeModel `tfsdk:"run_times"`
+	Budget     types.Int64    `tfsdk:"budget"`
+	Smart      types.Bool     `tfsdk:"smart"`
+}
+
+// Metadata returns the data source type name.
+func (d *programDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_program"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *programDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *programDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := programAttributes()
+	attributes["id"] = schema.StringAttribute{
+		Optional:    true,
+		Computed:    true,
+		Description: "ID of the program to read. Exactly one of id and program must be set.",
+	}
+	attributes["program"] = schema.StringAttribute{
+		Optional:    true,
+		Computed:    true,
+		Description: "Letter of the program to read, A to D.",
+		Validators: []validator.String{
+			programLetterValidator(),
+			stringvalidator.ExactlyOneOf(path.MatchRoot("id")),
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Reads a watering program of the device without managing it.",
+		Attributes:  attributes,
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *programDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config programDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	programs, err := d.data.api.programs(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Program", "Could not read the device programs: "+err.Error())
+		return
+	}
+
+	for _, p := range programs {
+		if config.ID.IsNull() && p.letter() == config.Program.ValueString() || !config.ID.IsNull() && p.ID == config.ID.ValueString() {
+			state := programData(p)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			return
+		}
+	}
+
+	which := "program " + config.Program.ValueString()
+	if !config.ID.IsNull() {
+		which = "program with id " + config.ID.ValueString()
+	}
+	resp.Diagnostics.AddError("Program Not Found", fmt.Sprintf("The device has no %s.", which))
+}
+
+// programAttributes returns the computed attributes of a program.
+func programAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:    true,
+			Description: "ID of the program.",
+		},
+		"program": schema.StringAttribute{
+			Computed:    true,
+			Description: "Letter of the program, A to D.",
+		},
+		"name": schema.StringAttribute{
+			Computed:    true,
+			Description: "Name of the program.",
+		},
+		"enabled": schema.BoolAttribute{
+			Computed:    true,
+			Description: "Whether the program runs.",
+		},
+		"frequency": schema.SingleNestedAttribute{
+			Computed:    true,
+			Description: "Which days the program runs.",
+			Attributes: map[string]schema.Attribute{
+				"type": schema.StringAttribute{
+					Computed:    true,
+					Description: "One of \"days\", \"interval\", \"odd\" or \"even\".",
+				},
+				"days": schema.ListAttribute{
+					Computed:    true,
+					ElementType: types.StringType,
+					Description: "Days of the week, such as \"mon\", for \"days\" programs.",
+				},
+				"interval": schema.Int64Attribute{
+					Computed:    true,
+					Description: "Days between runs for \"interval\" programs.",
+				},
+				"interval_start": schema.StringAttribute{
+					Computed:    true,
+					Description: "First day, as YYYY-MM-DD, of \"interval\" programs.",
+				},
+			},
+		},
+		"start_times": schema.ListAttribute{
+			Computed:    true,
+			ElementType: types.StringType,
+			Description: "Times of day, as HH:MM, the program starts.",
+		},
+		"run_times": schema.ListNestedAttribute{
+			Computed:    true,
+			Description: "Stations the program waters, in order, and for how many minutes.",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"station": schema.Int64Attribute{
+						Computed: true,
+					},
+					"minutes": schema.Int64Attribute{
+						Computed: true,
+					},
+				},
+			},
+		},
+		"budget": schema.Int64Attribute{
+			Computed:    true,
+			Description: "Percentage the run times are scaled by, null when not set.",
+		},
+		"smart": schema.BoolAttribute{
+			Computed:    true,
+			Description: "Whether the program is a smart watering program managed by B-hyve.",
+		},
+	}
+}
+
+// programData maps a program read from the API to its data source model.
+func programData(p apiProgram) programDataModel {
+	model := programDataModel{
+		ID:         types.StringValue(p.ID),
+		Program:    types.StringValue(p.letter()),
+		Name:       types.StringValue(p.Name),
+		Enabled:    types.BoolValue(p.Enabled),
+		Frequency:  frequencyModel{Type: p.Frequency.Type, Days: []string{}},
+		StartTimes: []string{},
+		RunTimes:   []runTimeModel{},
+		Budget:     types.Int64PointerValue(p.Budget),
+		Smart:      types.BoolValue(p.IsSmart),
+	}
+	model.StartTimes = append(model.StartTimes, p.StartTimes...)
+	for _, run := range p.RunTimes {
+		model.RunTimes = append(model.RunTimes, runTimeModel{Station: run.Station, Minutes: run.RunTime})
+	}
+
+	days := append([]int64(nil), p.Frequency.Days...)
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	for _, day := range days {
+		if day >= 0 && day <= 6 {
+			model.Frequency.Days = append(model.Frequency.Days, strings.ToLower(time.Weekday(day).String()[:3]))
+		}
+	}
+	model.Frequency.Interval = p.Frequency.Interval
+	if start, err := time.Parse(time.RFC3339, p.Frequency.IntervalStart); err == nil {
+		date := start.Format(time.DateOnly)
+		model.Frequency.IntervalStart = &date
+	}
+	return model
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestProgramDataSources(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/sprinkler_timer_programs": `[
+			{"id":"p2","program":"b","name":"Drip","enabled":false,"frequency":{"type":"interval","interval":3,"interval_start_time":"2024-06-01T00:00:00.000Z"},
+			 "start_times":["21:00"],"run_times":[{"station":3,"run_time":30}],"is_smart_program":true},
+			{"id":"p1","program":"a","name":"Lawn","enabled":true,"frequency":{"type":"days","days":[5,1,3]},
+			 "start_times":["05:00","18:30"],"run_times":[{"station":1,"run_time":20},{"station":2,"run_time":15}],"budget":120}
+		]`,
+	})
+	data := &bhyveProviderData{deviceID: "abc", api: api}
+
+	var got programDataModel
+	readDataSource(t, &programDataSource{data: data}, &programDataModel{Program: types.StringValue("A")}, &got)
+	if got.ID.ValueString() != "p1" || got.Name.ValueString() != "Lawn" || !got.Enabled.ValueBool() || got.Budget.ValueInt64() != 120 || got.Smart.ValueBool() {
+		t.Errorf("got %+v", got)
+	}
+	if days := got.Frequency.Days; len(days) != 3 || days[0] != "mon" || days[1] != "wed" || days[2] != "fri" {
+		t.Errorf("got days %v", days)
+	}
+	if len(got.StartTimes) != 2 || len(got.RunTimes) != 2 || got.RunTimes[1] != (runTimeModel{Station: 2, Minutes: 15}) {
+		t.Errorf("got start times %v, run times %v", got.StartTimes, got.RunTimes)
+	}
+
+	got = programDataModel{}
+	readDataSource(t, &programDataSource{data: data}, &programDataModel{ID: types.StringValue("p2")}, &got)
+	if got.Program.ValueString() != "B" || !got.Budget.IsNull() || !got.Smart.ValueBool() {
+		t.Errorf("got %+v", got)
+	}
+	if f := got.Frequency; f.Interval == nil || *f.Interval != 3 || f.IntervalStart == nil || *f.IntervalStart != "2024-06-01" || len(f.Days) != 0 {
+		t.Errorf("got frequency %+v", f)
+	}
+
+	var list programsDataSourceModel
+	readDataSource(t, &programsDataSource{data: data}, &programsDataSourceModel{}, &list)
+	if len(list.Programs) != 2 || list.Programs[0].Program.ValueString() != "A" || list.Programs[1].Program.ValueString() != "B" {
+		t.Errorf("got programs %+v", list.Programs)
+	}
+}
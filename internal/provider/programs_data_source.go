@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &programsDataSource{}
+	_ datasource.DataSourceWithConfigure = &programsDataSource{}
+)
+
+// NewProgramsDataSource returns the data source listing the programs of the
+// device.
+func NewProgramsDataSource() datasource.DataSource {
+	return &programsDataSource{}
+}
+
+// programsDataSource is the data source implementation.
+type programsDataSource struct {
+	data *bhyveProviderData
+}
+
+type programsDataSourceModel struct {
+	Programs []programDataModel `tfsdk:"programs"`
+}
+
+// Metadata returns the data source type name.
+func (d *programsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_programs"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *programsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *programsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the watering programs of the device without managing them.",
+		Attributes: map[string]schema.Attribute{
+			"programs": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Programs of the device in letter order.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: programAttributes(),
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *programsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	programs, err := d.data.api.programs(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Programs", "Could not read the device programs: "+err.Error())
+		return
+	}
+	sort.Slice(programs, func(i, j int) bool {
+		return programs[i].letter() < programs[j].letter()
+	})
+
+	state := programsDataSourceModel{Programs: []programDataModel{}}
+	for _, p := range programs {
+		state.Programs = append(state.Programs, programData(p))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -5,17 +5,25 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gillcaleb/orbit-bhyve-go-client/pkg/client"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
 )
 
 // Ensure bhyveProvider satisfies various provider interfaces.
@@ -32,11 +40,66 @@ type bhyveProvider struct {
 
 // bhyveProviderModel maps provider schema data to a Go type.
 type bhyveProviderModel struct {
-	DeviceId     types.String `tfsdk:"deviceid"`
-	Email        types.String `tfsdk:"email"`
-	Password     types.String `tfsdk:"password"`
+	DeviceId                 types.String  `tfsdk:"deviceid"`
+	Email                    types.String  `tfsdk:"email"`
+	Password                 types.String  `tfsdk:"password"`
+	RunWaterWarningGallons   types.Float64 `tfsdk:"run_water_warning_gallons"`
+	StationCount             types.Int64   `tfsdk:"station_count"`
+	MaxRetries               types.Int64   `tfsdk:"max_retries"`
+	MaxRetryElapsed          types.String  `tfsdk:"max_retry_elapsed"`
+	RequestsPerSecond        types.Float64 `tfsdk:"requests_per_second"`
+	RequestBurst             types.Int64   `tfsdk:"request_burst"`
+	ReadOnly                 types.Bool    `tfsdk:"read_only"`
+	DryRun                   types.Bool    `tfsdk:"dry_run"`
+	DryRunLog                types.String  `tfsdk:"dry_run_log"`
+	QuietHours               types.List    `tfsdk:"quiet_hours"`
+	MaxZoneMinutes           types.Int64   `tfsdk:"max_zone_minutes"`
+	MaxDailyMinutesPerDevice types.Int64   `tfsdk:"max_daily_minutes_per_device"`
 }
 
+// deviceClient is the part of the B-hyve client that commands the
+// configured device.
+type deviceClient interface {
+	Sync() error
+	StartZone(station, minutes int) error
+}
+
+// bhyveProviderData is the configured provider state handed to resources and
+// data sources through their Configure methods.
+type bhyveProviderData struct {
+	client   deviceClient
+	deviceID string
+
+	// runWaterWarningGallons is the increase in the water use of a run above
+	// which a plan warns. Zero disables the warning.
+	runWaterWarningGallons float64
+
+	// stationCount is the number of stations on the device. Zero when unknown.
+	stationCount int64
+
+	// retry is applied to idempotent REST requests and to reconnecting the
+	// events websocket.
+	retry retryPolicy
+
+	// limiter throttles calls to the B-hyve API across all resources and
+	// data sources of this provider instance.
+	limiter *rate.Limiter
+
+	// api makes the REST calls the client does not cover, and events
+	// delivers device events from the events websocket.
+	api    *apiClient
+	events *eventHub
+
+	// readOnly blocks resources from sending commands to the device.
+	readOnly bool
+
+	// dryRun, when set, records commands that change the device instead of
+	// sending them.
+	dryRun *dryRunRecorder
+
+	// guardrails refuse runs that break the provider's policies.
+	guardrails *guardrails
+}
 
 func (p *bhyveProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "bhyve"
@@ -46,64 +109,149 @@ func (p *bhyveProvider) Metadata(ctx context.Context, req provider.MetadataReque
 // Schema defines the provider-level schema for configuration data.
 func (p *bhyveProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
-			Attributes: map[string]schema.Attribute{
-					"deviceid": schema.StringAttribute{
-							Required: true,
-							Sensitive: true,
-					},
-					"email": schema.StringAttribute{
-							Required: true,
-					},
-					"password": schema.StringAttribute{
-							Required:  true,
-							Sensitive: true,
+		Attributes: map[string]schema.Attribute{
+			"deviceid": schema.StringAttribute{
+				Required:  true,
+				Sensitive: true,
+			},
+			"email": schema.StringAttribute{
+				Required: true,
+			},
+			"password": schema.StringAttribute{
+				Required:  true,
+				Sensitive: true,
+			},
+			"run_water_warning_gallons": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Warn during plan when a change increases the projected water use of a single zone run by more than this many gallons.",
+			},
+			"station_count": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of stations on the device. When set, plans are rejected for stations the device does not have.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, maxStations),
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of times a failed idempotent request to the B-hyve API is retried. Signing in is retried unless B-hyve rejects the email and password, and device commands are never retried. Defaults to 5.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_retry_elapsed": schema.StringAttribute{
+				Optional:    true,
+				Description: "Longest time to keep retrying a failed call to the B-hyve API, such as \"90s\". Defaults to \"2m\".",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Most calls per second made to the B-hyve API, shared by all resources and data sources. Defaults to 2.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0.01),
+				},
+			},
+			"request_burst": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of calls to the B-hyve API allowed at once before requests_per_second applies. Defaults to 5.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"read_only": schema.BoolAttribute{
+				Optional: true,
+				Description: "Fail every resource create, update and delete before it sends a command to the device, " +
+					"while data sources keep working. May also be set with the BHYVE_READ_ONLY environment variable.",
+			},
+			"dry_run": schema.BoolAttribute{
+				Optional: true,
+				Description: "Log the commands that would change the device, such as starting a zone, instead of sending them. " +
+					"Reads are still made.",
+			},
+			"dry_run_log": schema.StringAttribute{
+				Optional:    true,
+				Description: "File that dry_run appends each held back command to, one JSON object per line. Requires dry_run to be true.",
+			},
+			"quiet_hours": schema.ListNestedAttribute{
+				Optional: true,
+				Description: "Daily windows during which manual runs are refused, in the time zone of the machine running Terraform. " +
+					"A window may wrap past midnight.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"start": schema.StringAttribute{
+							Required:    true,
+							Description: "Start of the window in 24-hour HH:MM format.",
+							Validators:  []validator.String{startTimeValidator()},
+						},
+						"end": schema.StringAttribute{
+							Required:    true,
+							Description: "End of the window in 24-hour HH:MM format.",
+							Validators:  []validator.String{startTimeValidator()},
+						},
 					},
+				},
 			},
+			"max_zone_minutes": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Longest run, in minutes, allowed for a single zone.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"max_daily_minutes_per_device": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Most minutes the device may water in a day. Runs that would exceed it, counting the device's watering history, are refused.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+		},
 	}
 }
 
-
 func (p *bhyveProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	// Retrieve provider data from configuration
 	var config bhyveProviderModel
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-			return
+		return
 	}
 
 	// If practitioner provided a configuration value for any of the
 	// attributes, it must be a known value.
 
 	if config.DeviceId.IsUnknown() {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("deviceid"),
-					"Unknown DeviceId",
-					"The provider cannot create the Bhyve API client as there is an unknown configuration value for bhyve Device ID. "+
-							"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_DEVICEID environment variable.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deviceid"),
+			"Unknown DeviceId",
+			"The provider cannot create the Bhyve API client as there is an unknown configuration value for bhyve Device ID. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_DEVICEID environment variable.",
+		)
 	}
 
 	if config.Email.IsUnknown() {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("email"),
-					"Unknown Bhyve Email",
-					"The provider cannot create the  API client as there is an unknown configuration value for the Bhyve API username. "+
-							"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_USERNAME environment variable.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("email"),
+			"Unknown Bhyve Email",
+			"The provider cannot create the  API client as there is an unknown configuration value for the Bhyve API username. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_USERNAME environment variable.",
+		)
 	}
 
 	if config.Password.IsUnknown() {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("password"),
-					"Unknown Bhyve Password",
-					"The provider cannot create the Bhyve API client as there is an unknown configuration value for the Bhyve API password. "+
-							"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_PASSWORD environment variable.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Unknown Bhyve Password",
+			"The provider cannot create the Bhyve API client as there is an unknown configuration value for the Bhyve API password. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the BHYVE_PASSWORD environment variable.",
+		)
 	}
 
 	if resp.Diagnostics.HasError() {
-			return
+		return
 	}
 
 	// Default values to environment variables, but override
@@ -114,89 +262,234 @@ func (p *bhyveProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	password := os.Getenv("BHYVE_PASSWORD")
 
 	if !config.DeviceId.IsNull() {
-			deviceid = config.DeviceId.ValueString()
+		deviceid = config.DeviceId.ValueString()
 	}
 
 	if !config.Email.IsNull() {
-			email = config.Email.ValueString()
+		email = config.Email.ValueString()
 	}
 
 	if !config.Password.IsNull() {
-			password = config.Password.ValueString()
+		password = config.Password.ValueString()
+	}
+
+	var readOnly bool
+	if env := os.Getenv("BHYVE_READ_ONLY"); env != "" {
+		var err error
+		readOnly, err = strconv.ParseBool(env)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("read_only"),
+				"Invalid BHYVE_READ_ONLY Value",
+				fmt.Sprintf("The BHYVE_READ_ONLY environment variable must be true or false, got %q.", env),
+			)
+		}
+	}
+	if !config.ReadOnly.IsNull() {
+		readOnly = config.ReadOnly.ValueBool()
+	}
+
+	if !config.DryRunLog.IsNull() && !config.DryRun.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dry_run_log"),
+			"Dry Run Log Without Dry Run",
+			"dry_run_log is only written during a dry run. Set dry_run to true or remove dry_run_log.",
+		)
 	}
 
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
 	if deviceid == "" {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("host"),
-					"Missing Bhyve API Host",
-					"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API host. "+
-							"Set the host value in the configuration or use the BHYVE_DEVICEID environment variable. "+
-							"If either is already set, ensure the value is not empty.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("host"),
+			"Missing Bhyve API Host",
+			"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API host. "+
+				"Set the host value in the configuration or use the BHYVE_DEVICEID environment variable. "+
+				"If either is already set, ensure the value is not empty.",
+		)
 	}
 
 	if email == "" {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("email"),
-					"Missing Bhyve API email",
-					"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API username. "+
-							"Set the username value in the configuration or use the BHYVE_EMAIL environment variable. "+
-							"If either is already set, ensure the value is not empty.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("email"),
+			"Missing Bhyve API email",
+			"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API username. "+
+				"Set the username value in the configuration or use the BHYVE_EMAIL environment variable. "+
+				"If either is already set, ensure the value is not empty.",
+		)
 	}
 
 	if password == "" {
-			resp.Diagnostics.AddAttributeError(
-					path.Root("password"),
-					"Missing Bhyve API Password",
-					"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API password. "+
-							"Set the password value in the configuration or use the BHYVE_PASSWORD environment variable. "+
-							"If either is already set, ensure the value is not empty.",
-			)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Missing Bhyve API Password",
+			"The provider cannot create the Bhyve API client as there is a missing or empty value for the Bhyve API password. "+
+				"Set the password value in the configuration or use the BHYVE_PASSWORD environment variable. "+
+				"If either is already set, ensure the value is not empty.",
+		)
 	}
 
 	if resp.Diagnostics.HasError() {
-			return
+		return
 	}
-  
+
 	clientconfig := client.Config{
-		Endpoint: "https://api.orbitbhyve.com/v1",
-		Email: email,
+		Endpoint: bhyveEndpoint,
+		Email:    email,
 		Password: password,
 		DeviceId: deviceid,
-  }
+	}
+	retry := retryPolicy{
+		maxRetries: defaultMaxRetries,
+		maxElapsed: defaultMaxRetryElapsed,
+	}
+	if !config.MaxRetries.IsNull() {
+		retry.maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.MaxRetryElapsed.IsNull() {
+		// The value is checked by durationValidator.
+		retry.maxElapsed, _ = time.ParseDuration(config.MaxRetryElapsed.ValueString())
+	}
+
+	limit, burst := rate.Limit(defaultRequestsPerSecond), defaultRequestBurst
+	if !config.RequestsPerSecond.IsNull() {
+		limit = rate.Limit(config.RequestsPerSecond.ValueFloat64())
+	}
+	if !config.RequestBurst.IsNull() {
+		burst = int(config.RequestBurst.ValueInt64())
+	}
+
 	// Create a new Bhyve client using the configuration values
 	c := client.NewClient(clientconfig)
-	err := c.Init()
+	data := &bhyveProviderData{
+		client:                 c,
+		deviceID:               deviceid,
+		runWaterWarningGallons: config.RunWaterWarningGallons.ValueFloat64(),
+		stationCount:           config.StationCount.ValueInt64(),
+		retry:                  retry,
+		limiter:                rate.NewLimiter(limit, burst),
+		readOnly:               readOnly,
+	}
+	data.guardrails = &guardrails{
+		maxZoneMinutes:  config.MaxZoneMinutes.ValueInt64(),
+		maxDailyMinutes: config.MaxDailyMinutesPerDevice.ValueInt64(),
+	}
+	if !config.QuietHours.IsNull() && !config.QuietHours.IsUnknown() {
+		resp.Diagnostics.Append(config.QuietHours.ElementsAs(ctx, &data.guardrails.quietHours, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if config.DryRun.ValueBool() {
+		data.dryRun = &dryRunRecorder{path: config.DryRunLog.ValueString()}
+	}
+	data.api = newAPIClient(bhyveEndpoint, email, password, data)
+	data.events = newEventHub(bhyveEventsURL, data.api.session, retry, data.limiter)
+
+	err := data.signIn(ctx, c)
+	var rejected *apiError
+	if errors.As(err, &rejected) && signInRejected(rejected.StatusCode) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Bhyve Sign In Rejected",
+			"B-hyve rejected the email and password, check them and try again: "+err.Error(),
+		)
+		return
+	}
 	if err != nil {
-		  tflog.Error(ctx, "Error initializing client")
+		resp.Diagnostics.AddError(
+			"Unable to Create Bhyve API Client",
+			"An unexpected error occurred when signing in to the Bhyve API: "+err.Error(),
+		)
+		return
 	}
 
 	// Make the Bhyve client available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = c
-	resp.ResourceData = c
+	resp.DataSourceData = data
+	resp.ResourceData = data
 }
 
+// signIn signs the client in, retrying outages with the provider's policy.
+// The client cannot tell a rejected password from an outage, so the email
+// and password are first checked with the API client's own session. A
+// rejection is not retried, as signing in again could lock the account.
+func (d *bhyveProviderData) signIn(ctx context.Context, c *client.Client) error {
+	return d.retry.do(ctx, "sign in", func() error {
+		_, err := d.api.session(ctx)
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && signInRejected(apiErr.StatusCode) {
+			return &permanentError{err}
+		}
+		if err != nil {
+			return err
+		}
+		return d.call(ctx, func() error {
+			return initClient(c)
+		})
+	})
+}
+
+// signInRejected reports whether a session response status rejects the
+// email and password, rather than failing for another reason.
+func signInRejected(code int) bool {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// initClient signs the client in. The client panics on a session response
+// without a token, such as a gateway error page, so that is turned into an
+// error.
+func initClient(c *client.Client) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sign in response has no session: %v", r)
+		}
+	}()
+	return c.Init()
+}
+
+// stateOnlyTimeout describes the timeout of an operation that only changes
+// Terraform state. It is accepted, so every resource takes the same
+// timeouts block, but the operation has nothing on the devices to wait for.
+func stateOnlyTimeout(operation string) string {
+	return fmt.Sprintf(`A string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration), such as "30s" or "2h45m". `+
+		`%s only changes Terraform state and sends nothing to the devices, so it finishes well within any timeout.`, operation)
+}
 
 func (p *bhyveProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewZoneResource,
+		NewZoneRunResource,
+		NewDeviceSettingsResource,
+		NewZoneGroupResource,
+		NewZoneGroupRunResource,
 	}
 }
 
 func (p *bhyveProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewZoneDataSource,
+		NewZonesDataSource,
+		NewProgramDataSource,
+		NewProgramsDataSource,
+		NewEventLogDataSource,
+		NewWeatherDataSource,
+		NewWaterUsageDataSource,
+		NewLandscapeOptionsDataSource,
 	}
 }
 
 func (p *bhyveProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{
 		NewExampleFunction,
+		NewCheckRestrictionsFunction,
+		NewEstimateUsageFunction,
+		NewToICalFunction,
 	}
 }
 
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults for the request rate limit, overridable with the
+// requests_per_second and request_burst provider attributes. Terraform runs
+// up to ten operations at once, which is enough to trip B-hyve's throttling
+// when every one of them talks to the API.
+const (
+	defaultRequestsPerSecond = 2
+	defaultRequestBurst      = 5
+)
+
+// call makes one call to the B-hyve API through the client once the shared
+// rate limiter allows it. Device commands are not retried, as the client
+// keeps using a broken websocket for every later one, so a retry could not
+// succeed where the first attempt failed. Signing in is retried by signIn.
+func (d *bhyveProviderData) call(ctx context.Context, fn func() error) error {
+	if err := d.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// limitedTransport waits for the shared rate limiter before each request.
+type limitedTransport struct {
+	limiter *rate.Limiter
+	next    http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestProviderDataCallRateLimit(t *testing.T) {
+	data := &bhyveProviderData{
+		limiter: rate.NewLimiter(rate.Every(50*time.Millisecond), 1),
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := data.call(context.Background(), func() error { return nil }); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("three calls took %s, expected the limiter to space them out", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := data.call(ctx, func() error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 0 {
+		t.Errorf("expected a cancelled call not to be made, got %d calls and %v", calls, err)
+	}
+
+	// Client calls are made once, even when they fail.
+	failed := errors.New("connection reset")
+	calls = 0
+	err = data.call(context.Background(), func() error {
+		calls++
+		return failed
+	})
+	if !errors.Is(err, failed) || calls != 1 {
+		t.Errorf("expected a failed call to be made once, got %d calls and %v", calls, err)
+	}
+}
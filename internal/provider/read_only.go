@@ -0,0 +1,21 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// denyWrite adds an error to diags and returns true when the provider is read
+// only. Resources call it before sending any command to the device.
+func (d *bhyveProviderData) denyWrite(diags *diag.Diagnostics, operation string) bool {
+	if d == nil || !d.readOnly {
+		return false
+	}
+	diags.AddError(
+		"Provider Is Read Only",
+		fmt.Sprintf("Cannot %s: the provider is configured with read_only, or BHYVE_READ_ONLY, "+
+			"which blocks every change to the device. Data sources keep working.", operation),
+	)
+	return true
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Defaults for the retry policy, overridable with the max_retries and
+// max_retry_elapsed provider attributes.
+const (
+	defaultMaxRetries      = 5
+	defaultMaxRetryElapsed = 2 * time.Minute
+
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// retryPolicy retries failed calls with exponential backoff and full jitter.
+type retryPolicy struct {
+	maxRetries int
+	maxElapsed time.Duration
+
+	// sleep waits between attempts. It is replaced in tests.
+	sleep func(context.Context, time.Duration) error
+}
+
+// retryAfterError carries the wait a server asked for before the next attempt.
+type retryAfterError struct {
+	wait time.Duration
+	err  error
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// do calls fn until it succeeds, returns a permanentError, or the policy runs
+// out of attempts or time. The last error is returned.
+func (p retryPolicy) do(ctx context.Context, name string, fn func() error) error {
+	start := time.Now()
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= p.maxRetries {
+			return fmt.Errorf("%s failed after %d attempts: %w", name, attempt+1, err)
+		}
+
+		wait := p.backoff(attempt)
+		var retryAfter *retryAfterError
+		if errors.As(err, &retryAfter) && retryAfter.wait > 0 {
+			wait = retryAfter.wait
+		}
+		if p.maxElapsed > 0 && time.Since(start)+wait > p.maxElapsed {
+			return fmt.Errorf("%s failed, giving up after %s: %w", name, time.Since(start).Round(time.Second), err)
+		}
+
+		tflog.Warn(ctx, "Retrying failed call", map[string]interface{}{
+			"call":    name,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+			"error":   err.Error(),
+		})
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns a random delay up to the exponential ceiling for attempt.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := retryMaxDelay
+	if attempt < 16 {
+		if d := retryBaseDelay << attempt; d < ceiling {
+			ceiling = d
+		}
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryTransport retries idempotent HTTP requests that fail with a
+// connection error, 429 or a 5xx gateway status, honoring Retry-After.
+type retryTransport struct {
+	policy retryPolicy
+	next   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if !isIdempotent(req) {
+		return next.RoundTrip(req)
+	}
+
+	var resp *http.Response
+	err := t.policy.do(req.Context(), req.Method+" "+req.URL.Path, func() error {
+		if req.GetBody != nil && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return &permanentError{err}
+			}
+			req.Body = body
+		}
+
+		var err error
+		resp, err = next.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		if !isRetryableStatus(resp.StatusCode) {
+			return nil
+		}
+
+		statusErr := fmt.Errorf("server responded %s", resp.Status)
+		wait := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		resp.Body.Close()
+		return &retryAfterError{wait: wait, err: statusErr}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.GetBody != nil
+	}
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// It returns zero when the header is missing or malformed.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gillcaleb/orbit-bhyve-go-client/pkg/client"
+	"golang.org/x/time/rate"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var waits []time.Duration
+	policy := retryPolicy{
+		maxRetries: 3,
+		maxElapsed: time.Hour,
+		sleep: func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	calls := 0
+	err := policy.do(context.Background(), "test", func() error {
+		calls++
+		if calls == 1 {
+			return &retryAfterError{wait: 7 * time.Second, err: errors.New("throttled")}
+		}
+		if calls < 3 {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 3 || len(waits) != 2 || waits[0] != 7*time.Second || waits[1] > 2*retryBaseDelay {
+		t.Errorf("unexpected calls %d and waits %v", calls, waits)
+	}
+
+	calls = 0
+	err = policy.do(context.Background(), "test", func() error {
+		calls++
+		return errors.New("still down")
+	})
+	if err == nil || calls != 4 {
+		t.Errorf("expected failure after 4 calls, got %d calls and %v", calls, err)
+	}
+
+	calls = 0
+	err = policy.do(context.Background(), "test", func() error {
+		calls++
+		return &permanentError{errors.New("bad request")}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single call for a permanent error, got %d calls and %v", calls, err)
+	}
+
+	policy.maxElapsed = time.Second
+	err = policy.do(context.Background(), "test", func() error {
+		return &retryAfterError{wait: time.Minute, err: errors.New("throttled")}
+	})
+	if err == nil {
+		t.Error("expected a Retry-After beyond max_retry_elapsed to stop retrying")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.June, 3, 5, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"soon":                          0,
+		"Mon, 03 Jun 2024 05:00:30 GMT": 30 * time.Second,
+		"Mon, 03 Jun 2024 04:00:00 GMT": 0,
+	}
+	for header, want := range cases {
+		if got := retryAfter(header, now); got != want {
+			t.Errorf("%q: got %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := &http.Client{Transport: &retryTransport{policy: retryPolicy{
+		maxRetries: 5,
+		sleep: func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("got status %d after %d requests", resp.StatusCode, requests)
+	}
+	if len(waits) != 2 || waits[0] != time.Second {
+		t.Errorf("Retry-After not honored, waited %v", waits)
+	}
+
+	requests = 0
+	resp, err = client.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || requests != 1 {
+		t.Errorf("POST was retried: status %d after %d requests", resp.StatusCode, requests)
+	}
+}
+
+func TestSignIn(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantPosts int
+		rejected  bool
+	}{
+		// Each attempt signs in the API client, then the client.
+		{name: "accepted", statuses: []int{http.StatusOK, http.StatusOK}, wantPosts: 2},
+		{name: "outage", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}, wantPosts: 4},
+		// The API client keeps its session, so only the client signs in again.
+		{name: "client outage", statuses: []int{http.StatusOK, http.StatusBadGateway, http.StatusOK}, wantPosts: 3},
+		{name: "rejected", statuses: []int{http.StatusUnauthorized}, wantPosts: 1, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := http.StatusOK
+				if posts < len(tt.statuses) {
+					status = tt.statuses[posts]
+				}
+				posts++
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"orbit_session_token":"token","user_id":"me"}`))
+				} else {
+					_, _ = w.Write([]byte(`<html>error</html>`))
+				}
+			}))
+			defer server.Close()
+
+			data := &bhyveProviderData{
+				retry: retryPolicy{
+					maxRetries: 3,
+					maxElapsed: time.Hour,
+					sleep:      func(context.Context, time.Duration) error { return nil },
+				},
+				limiter: rate.NewLimiter(rate.Inf, 1),
+			}
+			data.api = newAPIClient(server.URL, "me@example.com", "secret", data)
+			err := data.signIn(context.Background(), client.NewClient(client.Config{Endpoint: server.URL}))
+
+			var apiErr *apiError
+			rejected := errors.As(err, &apiErr) && signInRejected(apiErr.StatusCode)
+			if (err != nil) != tt.rejected || rejected != tt.rejected {
+				t.Errorf("got %v, want rejected %t", err, tt.rejected)
+			}
+			if posts != tt.wantPosts {
+				t.Errorf("got %d sign in requests, want %d", posts, tt.wantPosts)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Frequency types understood by B-hyve programs.
+const (
+	frequencyDays     = "days"
+	frequencyInterval = "interval"
+	frequencyOdd      = "odd"
+	frequencyEven     = "even"
+)
+
+// startTimeRegex matches a 24-hour HH:MM start time.
+var startTimeRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// weekdays maps the accepted day spellings to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// runTimeAttrTypes, frequencyAttrTypes and programAttrTypes describe the
+// program object accepted by the provider functions.
+var runTimeAttrTypes = map[string]attr.Type{
+	"station": types.Int64Type,
+	"minutes": types.Int64Type,
+}
+
+var frequencyAttrTypes = map[string]attr.Type{
+	"type":           types.StringType,
+	"days":           types.ListType{ElemType: types.StringType},
+	"interval":       types.Int64Type,
+	"interval_start": types.StringType,
+}
+
+var programAttrTypes = map[string]attr.Type{
+	"name":        types.StringType,
+	"frequency":   types.ObjectType{AttrTypes: frequencyAttrTypes},
+	"start_times": types.ListType{ElemType: types.StringType},
+	"run_times":   types.ListType{ElemType: types.ObjectType{AttrTypes: runTimeAttrTypes}},
+}
+
+// programModel is a watering program: when it runs and which stations it
+// waters for how long. Stations in run_times water one after another.
+type programModel struct {
+	Name       *string        `tfsdk:"name"`
+	Frequency  frequencyModel `tfsdk:"frequency"`
+	StartTimes []string       `tfsdk:"start_times"`
+	RunTimes   []runTimeModel `tfsdk:"run_times"`
+}
+
+type frequencyModel struct {
+	Type          string   `tfsdk:"type"`
+	Days          []string `tfsdk:"days"`
+	Interval      *int64   `tfsdk:"interval"`
+	IntervalStart *string  `tfsdk:"interval_start"`
+}
+
+type runTimeModel struct {
+	Station int64 `tfsdk:"station"`
+	Minutes int64 `tfsdk:"minutes"`
+}
+
+// parseStartTime converts an HH:MM start time to minutes after midnight.
+func parseStartTime(s string) (int, error) {
+	if !startTimeRegex.MatchString(s) {
+		return 0, fmt.Errorf("start time %q must be in 24-hour HH:MM format", s)
+	}
+	h, _ := strconv.Atoi(s[:2])
+	m, _ := strconv.Atoi(s[3:])
+	return h*60 + m, nil
+}
+
+// parseWeekday converts a day name such as "mon" or "Monday" to a time.Weekday.
+func parseWeekday(s string) (time.Weekday, error) {
+	d, ok := weekdays[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown day %q, expected a day name such as \"mon\" or \"monday\"", s)
+	}
+	return d, nil
+}
+
+// label returns the program name, or a positional placeholder when unnamed.
+func (p programModel) label(index int) string {
+	if p.Name != nil && *p.Name != "" {
+		return *p.Name
+	}
+	return fmt.Sprintf("program %d", index)
+}
+
+// validate checks that the program is well formed.
+func (p programModel) validate() error {
+	if err := p.Frequency.validate(); err != nil {
+		return err
+	}
+	if len(p.StartTimes) == 0 {
+		return fmt.Errorf("at least one start time is required")
+	}
+	for _, s := range p.StartTimes {
+		if _, err := parseStartTime(s); err != nil {
+			return err
+		}
+	}
+	seen := make(map[int64]bool, len(p.RunTimes))
+	for _, rt := range p.RunTimes {
+		if rt.Station < 1 {
+			return fmt.Errorf("station %d is invalid, stations are numbered from 1", rt.Station)
+		}
+		if rt.Minutes < 1 {
+			return fmt.Errorf("station %d must run for at least one minute", rt.Station)
+		}
+		if seen[rt.Station] {
+			return fmt.Errorf("station %d is listed more than once", rt.Station)
+		}
+		seen[rt.Station] = true
+	}
+	return nil
+}
+
+func (f frequencyModel) validate() error {
+	switch f.Type {
+	case frequencyDays:
+		if len(f.Days) == 0 {
+			return fmt.Errorf("frequency type %q requires at least one day", frequencyDays)
+		}
+		for _, d := range f.Days {
+			if _, err := parseWeekday(d); err != nil {
+				return err
+			}
+		}
+	case frequencyInterval:
+		if f.Interval == nil || *f.Interval < 1 {
+			return fmt.Errorf("frequency type %q requires an interval of at least one day", frequencyInterval)
+		}
+		if f.IntervalStart != nil {
+			if _, err := time.Parse(time.DateOnly, *f.IntervalStart); err != nil {
+				return fmt.Errorf("interval_start %q must be a YYYY-MM-DD date", *f.IntervalStart)
+			}
+		}
+	case frequencyOdd, frequencyEven:
+	default:
+		return fmt.Errorf("unknown frequency type %q, expected one of %q, %q, %q or %q",
+			f.Type, frequencyDays, frequencyInterval, frequencyOdd, frequencyEven)
+	}
+	return nil
+}
+
+// runMinutes returns the total minutes of one run of the program.
+func (p programModel) runMinutes() int64 {
+	var total int64
+	for _, rt := range p.RunTimes {
+		total += rt.Minutes
+	}
+	return total
+}
+
+// weekdays returns the days of the week the program can water on. Odd, even
+// and most interval frequencies move through every day of the week.
+func (f frequencyModel) weekdays() []time.Weekday {
+	switch {
+	case f.Type == frequencyDays:
+		days := make([]time.Weekday, 0, len(f.Days))
+		for _, d := range f.Days {
+			wd, _ := parseWeekday(d)
+			days = append(days, wd)
+		}
+		return days
+	case f.Type == frequencyInterval && *f.Interval%7 == 0 && f.IntervalStart != nil:
+		start, _ := time.Parse(time.DateOnly, *f.IntervalStart)
+		return []time.Weekday{start.Weekday()}
+	default:
+		return []time.Weekday{
+			time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+			time.Thursday, time.Friday, time.Saturday,
+		}
+	}
+}
+
+// daysPerMonth is the length of an average Gregorian month.
+const daysPerMonth = 365.25 / 12
+
+// runsPerWeek returns the average number of times the program runs in a week.
+func (p programModel) runsPerWeek() float64 {
+	var days float64
+	switch p.Frequency.Type {
+	case frequencyDays:
+		seen := make(map[time.Weekday]bool, len(p.Frequency.Days))
+		for _, wd := range p.Frequency.weekdays() {
+			seen[wd] = true
+		}
+		days = float64(len(seen))
+	case frequencyInterval:
+		days = 7 / float64(*p.Frequency.Interval)
+	case frequencyOdd, frequencyEven:
+		days = 3.5
+	}
+	return days * float64(len(p.StartTimes))
+}
+
+// runsPerMonth returns the average number of times the program runs in a month.
+func (p programModel) runsPerMonth() float64 {
+	return p.runsPerWeek() * daysPerMonth / 7
+}
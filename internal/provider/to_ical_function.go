@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	// Embed the timezone database so the function behaves the same on hosts
+	// without one installed.
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = ToICalFunction{}
+)
+
+// icalEpoch is the first day events may start on for programs that have no
+// interval_start. Provider functions must be deterministic, so the calendar
+// cannot be anchored to the current date.
+var icalEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var icalSlugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+var icalWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func NewToICalFunction() function.Function {
+	return ToICalFunction{}
+}
+
+type ToICalFunction struct{}
+
+func (r ToICalFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "to_ical"
+}
+
+func (r ToICalFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Render programs as an iCalendar document",
+		MarkdownDescription: "Renders programs as an RFC 5545 iCalendar document with one recurring event per " +
+			"station and start time. Interval programs start on their `interval_start`, all other programs on " +
+			"their first matching day of 2024.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "programs",
+				MarkdownDescription: "Programs in the same shape accepted by `check_restrictions`.",
+				ElementType:         types.ObjectType{AttrTypes: programAttrTypes},
+			},
+			function.StringParameter{
+				Name:                "timezone",
+				MarkdownDescription: "IANA timezone of the controller, such as `America/Denver`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (r ToICalFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var programs []programModel
+	var timezone string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &programs, &timezone))
+
+	if resp.Error != nil {
+		return
+	}
+
+	for i, p := range programs {
+		if err := p.validate(); err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("%s: %s", p.label(i), err))
+			return
+		}
+	}
+
+	loc, err := loadTimeZone(timezone)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("unknown timezone %q", timezone))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, toICal(programs, loc)))
+}
+
+// toICal renders validated programs as an iCalendar document.
+func toICal(programs []programModel, loc *time.Location) string {
+	var b icalBuilder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:-//gillcaleb//terraform-provider-orbit-bhyve//EN")
+	b.line("CALSCALE:GREGORIAN")
+
+	year := icalEpoch.Year()
+	for _, p := range programs {
+		if anchor := p.icalAnchor(); anchor.Year() < year {
+			year = anchor.Year()
+		}
+	}
+	b.timezone(loc, year)
+
+	stamp := icalEpoch.Format("20060102T150405Z")
+	for i, p := range programs {
+		label := p.label(i)
+		slug := strings.Trim(icalSlugRegex.ReplaceAllString(strings.ToLower(label), "-"), "-")
+		anchor := p.icalAnchor()
+		for _, s := range p.StartTimes {
+			offset, _ := parseStartTime(s)
+			for _, rt := range p.RunTimes {
+				start := anchor.Add(time.Duration(offset) * time.Minute)
+				dayShift := int(start.Sub(anchor).Hours()) / 24
+
+				b.line("BEGIN:VEVENT")
+				b.line(fmt.Sprintf("UID:%d-%s-%s-station-%d@bhyve", i, slug, strings.Replace(s, ":", "", 1), rt.Station))
+				b.line("DTSTAMP:" + stamp)
+				b.line(fmt.Sprintf("DTSTART;TZID=%s:%s", loc.String(), start.Format("20060102T150405")))
+				b.line(fmt.Sprintf("DURATION:PT%dM", rt.Minutes))
+				b.line("RRULE:" + p.Frequency.rrule(dayShift))
+				b.line("SUMMARY:" + icalEscape(fmt.Sprintf("%s: station %d", label, rt.Station)))
+				b.line("DESCRIPTION:" + icalEscape(fmt.Sprintf("Waters station %d for %d minutes.", rt.Station, rt.Minutes)))
+				b.line("END:VEVENT")
+
+				offset += int(rt.Minutes)
+			}
+		}
+	}
+
+	b.line("END:VCALENDAR")
+	return b.String()
+}
+
+// icalAnchor returns the local midnight of the first day the program waters
+// on. The date is expressed in UTC fields and reinterpreted with a TZID.
+func (p programModel) icalAnchor() time.Time {
+	anchor := icalEpoch
+	if p.Frequency.Type == frequencyInterval && p.Frequency.IntervalStart != nil {
+		anchor, _ = time.Parse(time.DateOnly, *p.Frequency.IntervalStart)
+	}
+	days := make(map[time.Weekday]bool)
+	for _, wd := range p.Frequency.weekdays() {
+		days[wd] = true
+	}
+	for {
+		switch {
+		case p.Frequency.Type == frequencyOdd && anchor.Day()%2 == 0,
+			p.Frequency.Type == frequencyEven && anchor.Day()%2 != 0,
+			p.Frequency.Type == frequencyDays && !days[anchor.Weekday()]:
+			anchor = anchor.AddDate(0, 0, 1)
+		default:
+			return anchor
+		}
+	}
+}
+
+// rrule returns the recurrence rule of the frequency. dayShift moves the rule
+// forward for runs that begin after midnight following the program start.
+func (f frequencyModel) rrule(dayShift int) string {
+	switch f.Type {
+	case frequencyDays:
+		var days []string
+		seen := make(map[time.Weekday]bool)
+		for _, wd := range f.weekdays() {
+			wd = (wd + time.Weekday(dayShift)) % 7
+			if !seen[wd] {
+				seen[wd] = true
+				days = append(days, icalWeekdays[wd])
+			}
+		}
+		return "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	case frequencyInterval:
+		return fmt.Sprintf("FREQ=DAILY;INTERVAL=%d", *f.Interval)
+	default:
+		first := 1
+		if f.Type == frequencyEven {
+			first = 2
+		}
+		if dayShift%2 != 0 {
+			first = 3 - first
+		}
+		var days []string
+		for d := first; d <= 31; d += 2 {
+			days = append(days, fmt.Sprint(d))
+		}
+		return "FREQ=MONTHLY;BYMONTHDAY=" + strings.Join(days, ",")
+	}
+}
+
+// loadTimeZone loads the IANA time zone name. Unlike time.LoadLocation it
+// rejects "" and "Local", which depend on the machine running Terraform.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// icalBuilder accumulates content lines, folding them at 75 octets and
+// terminating them with CRLF as RFC 5545 requires.
+type icalBuilder struct {
+	strings.Builder
+}
+
+func (b *icalBuilder) line(s string) {
+	// Continuation lines start with a space, which counts toward their 75.
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// timezone writes a VTIMEZONE for loc, derived from the offset transitions
+// in the given year and repeated yearly.
+func (b *icalBuilder) timezone(loc *time.Location, year int) {
+	b.line("BEGIN:VTIMEZONE")
+	b.line("TZID:" + loc.String())
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	prev := start.In(loc)
+	transitions := 0
+	for t := start.Add(time.Hour); t.Before(end); t = t.Add(time.Hour) {
+		_, before := prev.Zone()
+		_, after := t.In(loc).Zone()
+		if before == after {
+			prev = t.In(loc)
+			continue
+		}
+		// Narrow the change down to the minute.
+		lo, hi := prev.UTC(), t
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, off := mid.In(loc).Zone(); off == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		b.observance(hi.In(loc), before)
+		transitions++
+		prev = t.In(loc)
+	}
+
+	if transitions == 0 {
+		name, off := start.In(loc).Zone()
+		b.line("BEGIN:STANDARD")
+		b.line("DTSTART:19700101T000000")
+		b.line("TZOFFSETFROM:" + icalOffset(off))
+		b.line("TZOFFSETTO:" + icalOffset(off))
+		b.line("TZNAME:" + icalEscape(name))
+		b.line("END:STANDARD")
+	}
+
+	b.line("END:VTIMEZONE")
+}
+
+// observance writes the STANDARD or DAYLIGHT component starting at t, the
+// first instant of the new offset. from is the offset in effect before t.
+func (b *icalBuilder) observance(t time.Time, from int) {
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	name, to := t.Zone()
+	wall := t.UTC().Add(time.Duration(from) * time.Second)
+
+	week := (wall.Day()-1)/7 + 1
+	if wall.AddDate(0, 0, 7).Month() != wall.Month() {
+		week = -1
+	}
+
+	b.line("BEGIN:" + kind)
+	b.line("DTSTART:" + wall.Format("20060102T150405"))
+	b.line("TZOFFSETFROM:" + icalOffset(from))
+	b.line("TZOFFSETTO:" + icalOffset(to))
+	b.line(fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", int(wall.Month()), week, icalWeekdays[wall.Weekday()]))
+	b.line("TZNAME:" + icalEscape(name))
+	b.line("END:" + kind)
+}
+
+// icalOffset formats a UTC offset in seconds as +hhmm.
+func icalOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icalEscape escapes a TEXT property value.
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
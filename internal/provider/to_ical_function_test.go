@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToICal(t *testing.T) {
+	loc, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "Front lawn, north"
+	programs := []programModel{{
+		Name:       &name,
+		Frequency:  frequencyModel{Type: frequencyDays, Days: []string{"tue", "sat"}},
+		StartTimes: []string{"23:50"},
+		RunTimes:   []runTimeModel{{Station: 1, Minutes: 15}, {Station: 2, Minutes: 20}},
+	}}
+
+	got := toICal(programs, loc)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"TZID:America/Denver\r\n",
+		"DTSTART:20240310T020000\r\nTZOFFSETFROM:-0700\r\nTZOFFSETTO:-0600\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU\r\n",
+		"UID:0-front-lawn-north-2350-station-1@bhyve\r\n",
+		"DTSTART;TZID=America/Denver:20240102T235000\r\nDURATION:PT15M\r\nRRULE:FREQ=WEEKLY;BYDAY=TU,SA\r\n",
+		// Station 2 starts after midnight, so it runs a day later.
+		"DTSTART;TZID=America/Denver:20240103T000500\r\nDURATION:PT20M\r\nRRULE:FREQ=WEEKLY;BYDAY=WE,SU\r\n",
+		"SUMMARY:Front lawn\\, north: station 2\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestFrequencyRRule(t *testing.T) {
+	interval := int64(3)
+	cases := []struct {
+		frequency frequencyModel
+		dayShift  int
+		want      string
+	}{
+		{frequencyModel{Type: frequencyDays, Days: []string{"sat", "mon"}}, 0, "FREQ=WEEKLY;BYDAY=SA,MO"},
+		{frequencyModel{Type: frequencyDays, Days: []string{"sat", "mon"}}, 1, "FREQ=WEEKLY;BYDAY=SU,TU"},
+		{frequencyModel{Type: frequencyInterval, Interval: &interval}, 1, "FREQ=DAILY;INTERVAL=3"},
+		{frequencyModel{Type: frequencyOdd}, 0, "FREQ=MONTHLY;BYMONTHDAY=1,3,5,7,9,11,13,15,17,19,21,23,25,27,29,31"},
+		{frequencyModel{Type: frequencyOdd}, 1, "FREQ=MONTHLY;BYMONTHDAY=2,4,6,8,10,12,14,16,18,20,22,24,26,28,30"},
+	}
+
+	for _, tc := range cases {
+		if got := tc.frequency.rrule(tc.dayShift); got != tc.want {
+			t.Errorf("%+v shifted %d: got %q, want %q", tc.frequency, tc.dayShift, got, tc.want)
+		}
+	}
+}
+
+func TestICalBuilderFolding(t *testing.T) {
+	for _, value := range []string{
+		"DESCRIPTION:" + strings.Repeat("ü", 60),
+		"DESCRIPTION:" + strings.Repeat("a", 300),
+		"DESCRIPTION:" + strings.Repeat("€", 200),
+	} {
+		var b icalBuilder
+		b.line(value)
+
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+		for _, l := range lines {
+			if len(l) > 75 {
+				t.Errorf("line of %d octets is longer than 75", len(l))
+			}
+		}
+		if len(value) > 200 && len(lines) < 3 {
+			t.Errorf("got %d lines for a %d octet value, want full continuation lines", len(lines), len(value))
+		}
+		if unfolded := strings.ReplaceAll(b.String(), "\r\n ", ""); unfolded != value+"\r\n" {
+			t.Errorf("unexpected unfolded line %q", unfolded)
+		}
+	}
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"America/Denver": true,
+		"UTC":            true,
+		"Local":          false,
+		"":               false,
+		"Mars/Olympus":   false,
+	} {
+		if _, err := loadTimeZone(name); (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid %t", name, err, valid)
+		}
+	}
+}
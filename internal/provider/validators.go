@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Limits shared by the zone and program schemas. The largest B-hyve
+// controllers have 16 stations and the app caps a manual run at four hours.
+const (
+	maxStations   = 16
+	maxRunMinutes = 240
+)
+
+// programLetters are the program slots of a B-hyve controller.
+var programLetters = []string{"A", "B", "C", "D"}
+
+// startTimeValidator checks that a string is a 24-hour HH:MM start time.
+func startTimeValidator() validator.String {
+	return stringvalidator.RegexMatches(startTimeRegex, "must be a 24-hour time in HH:MM format")
+}
+
+// programLetterValidator checks that a string names a program slot.
+func programLetterValidator() validator.String {
+	return stringvalidator.OneOf(programLetters...)
+}
+
+var _ validator.List = uniqueStationsValidator{}
+
+// uniqueStationsValidator checks that a list of run objects does not list
+// the same station twice. Objects with a device_id may list the same station
+// of different devices.
+type uniqueStationsValidator struct{}
+
+func (v uniqueStationsValidator) Description(_ context.Context) string {
+	return "each station may appear only once"
+}
+
+func (v uniqueStationsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v uniqueStationsValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	type stationKey struct {
+		deviceID string
+		station  int64
+	}
+	seen := make(map[stationKey]bool)
+	for i, elem := range req.ConfigValue.Elements() {
+		run, ok := elem.(types.Object)
+		if !ok || run.IsNull() || run.IsUnknown() {
+			continue
+		}
+		station, ok := run.Attributes()["station"].(types.Int64)
+		if !ok || station.IsNull() || station.IsUnknown() {
+			continue
+		}
+		deviceID, ok := run.Attributes()["device_id"].(types.String)
+		if ok && deviceID.IsUnknown() {
+			continue
+		}
+		key := stationKey{deviceID: deviceID.ValueString(), station: station.ValueInt64()}
+		if seen[key] {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtListIndex(i).AtName("station"),
+				"Duplicate Station",
+				fmt.Sprintf("Station %d is listed more than once. %s.", station.ValueInt64(), v.Description(ctx)),
+			)
+		}
+		seen[key] = true
+	}
+}
+
+var _ validator.String = durationValidator{}
+
+// durationValidator checks that a string is a positive Go duration such as
+// "90s" or "2m".
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return `must be a positive duration such as "90s" or "2m"`
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if d, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Value %q %s.", req.ConfigValue.ValueString(), v.Description(ctx)),
+		)
+	}
+}
+
+var _ validator.String = regexValidator{}
+
+// regexValidator checks that a string is a valid regular expression.
+type regexValidator struct{}
+
+func (v regexValidator) Description(_ context.Context) string {
+	return "must be a valid regular expression"
+}
+
+func (v regexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v regexValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Regular Expression",
+			fmt.Sprintf("Value %q %s: %s.", req.ConfigValue.ValueString(), v.Description(ctx), err),
+		)
+	}
+}
+
+var _ validator.String = timestampValidator{}
+
+// timestampValidator checks that a string is an RFC 3339 timestamp, as
+// returned by Terraform's timestamp function.
+type timestampValidator struct{}
+
+func (v timestampValidator) Description(_ context.Context) string {
+	return `must be an RFC 3339 timestamp such as "2024-06-01T05:00:00Z"`
+}
+
+func (v timestampValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timestampValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Timestamp",
+			fmt.Sprintf("Value %q %s.", req.ConfigValue.ValueString(), v.Description(ctx)),
+		)
+	}
+}
+
+var _ validator.String = timeZoneValidator{}
+
+// timeZoneValidator checks that a string is an IANA time zone name such as
+// "America/Denver".
+type timeZoneValidator struct{}
+
+func (v timeZoneValidator) Description(_ context.Context) string {
+	return `must be an IANA time zone such as "America/Denver" or "UTC"`
+}
+
+func (v timeZoneValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timeZoneValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	name := req.ConfigValue.ValueString()
+	if _, err := loadTimeZone(name); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Time Zone",
+			fmt.Sprintf("Value %q %s.", name, v.Description(ctx)),
+		)
+	}
+}
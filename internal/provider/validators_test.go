@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUniqueStationsValidator(t *testing.T) {
+	run := func(station int64) attr.Value {
+		return types.ObjectValueMust(runTimeAttrTypes, map[string]attr.Value{
+			"station": types.Int64Value(station),
+			"minutes": types.Int64Value(10),
+		})
+	}
+	elemType := types.ObjectType{AttrTypes: runTimeAttrTypes}
+	zoneAttrTypes := map[string]attr.Type{"device_id": types.StringType, "station": types.Int64Type}
+	zone := func(deviceID types.String, station int64) attr.Value {
+		return types.ObjectValueMust(zoneAttrTypes, map[string]attr.Value{
+			"device_id": deviceID,
+			"station":   types.Int64Value(station),
+		})
+	}
+	zoneType := types.ObjectType{AttrTypes: zoneAttrTypes}
+
+	cases := map[string]struct {
+		value     types.List
+		wantError bool
+	}{
+		"null":      {types.ListNull(elemType), false},
+		"unique":    {types.ListValueMust(elemType, []attr.Value{run(1), run(2)}), false},
+		"duplicate": {types.ListValueMust(elemType, []attr.Value{run(1), run(2), run(1)}), true},
+		"other device": {types.ListValueMust(zoneType, []attr.Value{
+			zone(types.StringNull(), 1), zone(types.StringValue("def"), 1)}), false},
+		"same device": {types.ListValueMust(zoneType, []attr.Value{
+			zone(types.StringValue("def"), 1), zone(types.StringValue("def"), 1)}), true},
+		"unknown device": {types.ListValueMust(zoneType, []attr.Value{
+			zone(types.StringValue("def"), 1), zone(types.StringUnknown(), 1)}), false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := validator.ListRequest{Path: path.Root("run_times"), ConfigValue: tc.value}
+			resp := &validator.ListResponse{}
+			uniqueStationsValidator{}.ValidateList(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tc.wantError {
+				t.Errorf("got errors %v, want error %t", resp.Diagnostics, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestStartTimeValidator(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"05:00": true,
+		"23:59": true,
+		"24:00": false,
+		"5:00":  false,
+		"05:60": false,
+	} {
+		req := validator.StringRequest{Path: path.Root("start_time"), ConfigValue: types.StringValue(value)}
+		resp := &validator.StringResponse{}
+		startTimeValidator().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: got errors %v, want valid %t", value, resp.Diagnostics, valid)
+		}
+	}
+}
+
+func TestRegexValidator(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"^Front":   true,
+		"lawn|bed": true,
+		"(":        false,
+		"[a-":      false,
+	} {
+		req := validator.StringRequest{Path: path.Root("name_regex"), ConfigValue: types.StringValue(value)}
+		resp := &validator.StringResponse{}
+		regexValidator{}.ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: got errors %v, want valid %t", value, resp.Diagnostics, valid)
+		}
+	}
+}
+
+func TestTimestampValidator(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"2024-06-01T05:00:00Z":      true,
+		"2024-06-01T05:00:00-07:00": true,
+		"2024-06-01":                false,
+		"yesterday":                 false,
+	} {
+		req := validator.StringRequest{Path: path.Root("since"), ConfigValue: types.StringValue(value)}
+		resp := &validator.StringResponse{}
+		timestampValidator{}.ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: got errors %v, want valid %t", value, resp.Diagnostics, valid)
+		}
+	}
+}
+
+func TestTimeZoneValidator(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"UTC":            true,
+		"America/Denver": true,
+		"Local":          false,
+		"":               false,
+		"Mars/Olympus":   false,
+	} {
+		req := validator.StringRequest{Path: path.Root("timezone"), ConfigValue: types.StringValue(value)}
+		resp := &validator.StringResponse{}
+		timeZoneValidator{}.ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: got errors %v, want valid %t", value, resp.Diagnostics, valid)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	// Time zone names must resolve on machines without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Periods the water usage is totalled by. Weeks start on Monday.
+const (
+	periodDay   = "day"
+	periodWeek  = "week"
+	periodMonth = "month"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &waterUsageDataSource{}
+	_ datasource.DataSourceWithConfigure = &waterUsageDataSource{}
+)
+
+// NewWaterUsageDataSource returns the data source totalling the watering
+// history of the device.
+func NewWaterUsageDataSource() datasource.DataSource {
+	return &waterUsageDataSource{}
+}
+
+// waterUsageDataSource is the data source implementation.
+type waterUsageDataSource struct {
+	data *bhyveProviderData
+}
+
+type waterUsageDataSourceModel struct {
+	Since        types.String       `tfsdk:"since"`
+	Until        types.String       `tfsdk:"until"`
+	Period       types.String       `tfsdk:"period"`
+	Unit         types.String       `tfsdk:"unit"`
+	Timezone     types.String       `tfsdk:"timezone"`
+	TotalMinutes types.Float64      `tfsdk:"total_minutes"`
+	TotalVolume  types.Float64      `tfsdk:"total_volume"`
+	Zones        []zoneTotalModel   `tfsdk:"zones"`
+	Periods      []periodUsageModel `tfsdk:"periods"`
+}
+
+type zoneTotalModel struct {
+	Station types.Int64   `tfsdk:"station"`
+	Minutes types.Float64 `tfsdk:"minutes"`
+	Volume  types.Float64 `tfsdk:"volume"`
+}
+
+type periodUsageModel struct {
+	Start   types.String     `tfsdk:"start"`
+	Minutes types.Float64    `tfsdk:"minutes"`
+	Volume  types.Float64    `tfsdk:"volume"`
+	Zones   []zoneTotalModel `tfsdk:"zones"`
+}
+
+// Metadata returns the data source type name.
+func (d *waterUsageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_water_usage"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *waterUsageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *waterUsageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	zoneTotals := func(description string) schema.ListNestedAttribute {
+		return schema.ListNestedAttribute{
+			Computed:    true,
+			Description: description,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"station": schema.Int64Attribute{
+						Computed:    true,
+						Description: "Station number of the zone.",
+					},
+					"minutes": schema.Float64Attribute{
+						Computed:    true,
+						Description: "Minutes the zone watered.",
+					},
+					"volume": schema.Float64Attribute{
+						Computed:    true,
+						Description: "Water the zone used, in unit.",
+					},
+				},
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Totals the watering history of the device by zone and by day, week or month. " +
+			"Volumes are those the device reports.",
+		Attributes: map[string]schema.Attribute{
+			"since": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only count runs starting at or after this RFC 3339 time.",
+				Validators:  []validator.String{timestampValidator{}},
+			},
+			"until": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only count runs starting before this RFC 3339 time.",
+				Validators:  []validator.String{timestampValidator{}},
+			},
+			"period": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Period of the totals in periods: \"day\", \"week\" starting on Monday, or \"month\". Defaults to \"month\".",
+				Validators:  []validator.String{stringvalidator.OneOf(periodDay, periodWeek, periodMonth)},
+			},
+			"unit": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Volume unit of the totals, \"gallons\" or \"liters\". Defaults to \"gallons\".",
+				Validators:  []validator.String{stringvalidator.OneOf(unitGallons, unitLiters)},
+			},
+			"timezone": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "IANA time zone the days, weeks and months are taken in. Defaults to \"UTC\".",
+				Validators:  []validator.String{timeZoneValidator{}},
+			},
+			"total_minutes": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Minutes the device watered.",
+			},
+			"total_volume": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Water the device used, in unit.",
+			},
+			"zones": zoneTotals("Totals of each zone in station order."),
+			"periods": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Totals of each period with watering, oldest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"start": schema.StringAttribute{
+							Computed:    true,
+							Description: "First day of the period, as YYYY-MM-DD.",
+						},
+						"minutes": schema.Float64Attribute{
+							Computed:    true,
+							Description: "Minutes the device watered in the period.",
+						},
+						"volume": schema.Float64Attribute{
+							Computed:    true,
+							Description: "Water the device used in the period, in unit.",
+						},
+						"zones": zoneTotals("Totals of each zone in the period, in station order."),
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *waterUsageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state waterUsageDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Period.IsNull() {
+		state.Period = types.StringValue(periodMonth)
+	}
+	if state.Unit.IsNull() {
+		state.Unit = types.StringValue(unitGallons)
+	}
+	if state.Timezone.IsNull() {
+		state.Timezone = types.StringValue("UTC")
+	}
+
+	// The values are checked by their validators.
+	var report usageReport
+	report.period = state.Period.ValueString()
+	report.location, _ = time.LoadLocation(state.Timezone.ValueString())
+	if state.Unit.ValueString() == unitLiters {
+		report.factor = litersPerGallon
+	} else {
+		report.factor = 1
+	}
+	if !state.Since.IsNull() {
+		report.since, _ = time.Parse(time.RFC3339, state.Since.ValueString())
+	}
+	if !state.Until.IsNull() {
+		report.until, _ = time.Parse(time.RFC3339, state.Until.ValueString())
+	}
+
+	events, err := d.data.api.wateringEvents(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Water Usage", "Could not read the watering history: "+err.Error())
+		return
+	}
+
+	total, periods := report.aggregate(events)
+	state.TotalMinutes = types.Float64Value(total.minutes)
+	state.TotalVolume = types.Float64Value(total.volume)
+	state.Zones = total.zoneModels()
+	state.Periods = []periodUsageModel{}
+	for _, p := range periods {
+		state.Periods = append(state.Periods, periodUsageModel{
+			Start:   types.StringValue(p.start),
+			Minutes: types.Float64Value(p.minutes),
+			Volume:  types.Float64Value(p.volume),
+			Zones:   p.zoneModels(),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// usageReport totals watering history. Unset since and until count every
+// run.
+type usageReport struct {
+	since, until time.Time
+	period       string
+	location     *time.Location
+	// factor converts gallons to the unit of the report.
+	factor float64
+}
+
+// usageTotal is the water used overall or in one period.
+type usageTotal struct {
+	start   string
+	minutes float64
+	volume  float64
+	zones   map[int64]*usageTotal
+}
+
+func (t *usageTotal) add(station int64, minutes, volume float64) {
+	t.minutes += minutes
+	t.volume += volume
+	if t.zones == nil {
+		t.zones = map[int64]*usageTotal{}
+	}
+	zone, ok := t.zones[station]
+	if !ok {
+		zone = &usageTotal{}
+		t.zones[station] = zone
+	}
+	zone.minutes += minutes
+	zone.volume += volume
+}
+
+func (t *usageTotal) zoneModels() []zoneTotalModel {
+	models := []zoneTotalModel{}
+	for station, zone := range t.zones {
+		models = append(models, zoneTotalModel{
+			Station: types.Int64Value(station),
+			Minutes: types.Float64Value(zone.minutes),
+			Volume:  types.Float64Value(zone.volume),
+		})
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Station.ValueInt64() < models[j].Station.ValueInt64()
+	})
+	return models
+}
+
+// aggregate returns the overall total of events and the totals of each
+// period with watering, oldest first.
+func (r usageReport) aggregate(events []apiWateringEvent) (usageTotal, []*usageTotal) {
+	var total usageTotal
+	byStart := map[string]*usageTotal{}
+	for _, ev := range events {
+		for _, irrigation := range ev.Irrigation {
+			start := irrigation.StartTime
+			if start == "" {
+				start = ev.StartTime
+			}
+			at, err := time.Parse(time.RFC3339, start)
+			if err != nil {
+				continue
+			}
+			if !r.since.IsZero() && at.Before(r.since) || !r.until.IsZero() && !at.Before(r.until) {
+				continue
+			}
+
+			volume := irrigation.WaterVolumeGal * r.factor
+			total.add(irrigation.Station, irrigation.RunTime, volume)
+
+			key := r.periodStart(at)
+			period, ok := byStart[key]
+			if !ok {
+				period = &usageTotal{start: key}
+				byStart[key] = period
+			}
+			period.add(irrigation.Station, irrigation.RunTime, volume)
+		}
+	}
+
+	periods := make([]*usageTotal, 0, len(byStart))
+	for _, p := range byStart {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].start < periods[j].start
+	})
+	return total, periods
+}
+
+// periodStart returns the first day, as YYYY-MM-DD, of the period holding t.
+func (r usageReport) periodStart(t time.Time) string {
+	t = t.In(r.location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.location)
+	switch r.period {
+	case periodWeek:
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case periodMonth:
+		day = day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.Format(time.DateOnly)
+}
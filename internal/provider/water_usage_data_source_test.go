@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWaterUsageDataSource(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/watering_events/abc": `[
+			{"start_time":"2024-05-31T12:00:00Z","irrigation":[{"station":1,"run_time":10,"water_volume_gal":20}]},
+			{"start_time":"2024-06-01T02:00:00Z","irrigation":[
+				{"station":1,"run_time":10,"water_volume_gal":20},
+				{"station":2,"start_time":"2024-06-01T02:10:00Z","run_time":5,"water_volume_gal":4}
+			]},
+			{"start_time":"2024-06-03T12:00:00Z","irrigation":[{"station":2,"run_time":15,"water_volume_gal":12}]}
+		]`,
+	})
+	ds := &waterUsageDataSource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+
+	type period struct {
+		start   string
+		minutes float64
+	}
+	tests := []struct {
+		name    string
+		config  waterUsageDataSourceModel
+		minutes float64
+		volume  float64
+		periods []period
+	}{
+		{
+			name:    "defaults",
+			minutes: 40, volume: 56,
+			periods: []period{{"2024-05-01", 10}, {"2024-06-01", 30}},
+		},
+		{
+			name:    "days in time zone",
+			config:  waterUsageDataSourceModel{Period: types.StringValue("day"), Timezone: types.StringValue("America/Denver")},
+			minutes: 40, volume: 56,
+			periods: []period{{"2024-05-31", 25}, {"2024-06-03", 15}},
+		},
+		{
+			name:    "weeks",
+			config:  waterUsageDataSourceModel{Period: types.StringValue("week")},
+			minutes: 40, volume: 56,
+			periods: []period{{"2024-05-27", 25}, {"2024-06-03", 15}},
+		},
+		{
+			name:    "range in liters",
+			config:  waterUsageDataSourceModel{Since: types.StringValue("2024-06-01T00:00:00Z"), Until: types.StringValue("2024-06-03T12:00:00Z"), Unit: types.StringValue("liters")},
+			minutes: 15, volume: 24 * litersPerGallon,
+			periods: []period{{"2024-06-01", 15}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got waterUsageDataSourceModel
+			readDataSource(t, ds, &tt.config, &got)
+
+			if got.TotalMinutes.ValueFloat64() != tt.minutes || math.Abs(got.TotalVolume.ValueFloat64()-tt.volume) > 1e-9 {
+				t.Errorf("got totals %v minutes, %v volume, want %v, %v", got.TotalMinutes, got.TotalVolume, tt.minutes, tt.volume)
+			}
+			if len(got.Periods) != len(tt.periods) {
+				t.Fatalf("got periods %+v, want %v", got.Periods, tt.periods)
+			}
+			for i, p := range got.Periods {
+				if p.Start.ValueString() != tt.periods[i].start || p.Minutes.ValueFloat64() != tt.periods[i].minutes {
+					t.Errorf("got period %+v, want %v", p, tt.periods[i])
+				}
+			}
+		})
+	}
+
+	var got waterUsageDataSourceModel
+	readDataSource(t, ds, &waterUsageDataSourceModel{}, &got)
+	if got.Period.ValueString() != "month" || got.Unit.ValueString() != "gallons" || got.Timezone.ValueString() != "UTC" {
+		t.Errorf("got defaults %v, %v, %v", got.Period, got.Unit, got.Timezone)
+	}
+	if len(got.Zones) != 2 || got.Zones[0].Volume.ValueFloat64() != 40 || got.Zones[1].Minutes.ValueFloat64() != 20 {
+		t.Errorf("got zones %+v", got.Zones)
+	}
+	if june := got.Periods[1]; len(june.Zones) != 2 || june.Zones[0].Minutes.ValueFloat64() != 10 || june.Zones[1].Volume.ValueFloat64() != 16 {
+		t.Errorf("got June zones %+v", june.Zones)
+	}
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &weatherDataSource{}
+	_ datasource.DataSourceWithConfigure = &weatherDataSource{}
+)
+
+// NewWeatherDataSource returns the data source reading the forecast smart
+// watering uses for the device.
+func NewWeatherDataSource() datasource.DataSource {
+	return &weatherDataSource{}
+}
+
+// weatherDataSource is the data source implementation.
+type weatherDataSource struct {
+	data *bhyveProviderData
+}
+
+type weatherDataSourceModel struct {
+	Forecast     []forecastDayModel `tfsdk:"forecast"`
+	UpcomingRuns []plannedRunModel  `tfsdk:"upcoming_runs"`
+}
+
+type forecastDayModel struct {
+	Date                     types.String  `tfsdk:"date"`
+	Precipitation            types.Float64 `tfsdk:"precipitation"`
+	PrecipitationProbability types.Float64 `tfsdk:"precipitation_probability"`
+	TemperatureHigh          types.Float64 `tfsdk:"temperature_high"`
+	TemperatureLow           types.Float64 `tfsdk:"temperature_low"`
+	WindSpeed                types.Float64 `tfsdk:"wind_speed"`
+}
+
+type plannedRunModel struct {
+	Program    types.String `tfsdk:"program"`
+	StartTime  types.String `tfsdk:"start_time"`
+	Skip       types.Bool   `tfsdk:"skip"`
+	SkipReason types.String `tfsdk:"skip_reason"`
+}
+
+// Metadata returns the data source type name.
+func (d *weatherDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_weather"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *weatherDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *weatherDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	forecastAttribute := func(description string) schema.Float64Attribute {
+		return schema.Float64Attribute{Computed: true, Description: description + " Null when the forecast lacks it."}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Reads the weather forecast smart watering uses for the device, and whether the controller " +
+			"plans to run or skip its upcoming program runs.",
+		Attributes: map[string]schema.Attribute{
+			"forecast": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Forecast of the coming days in date order.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"date": schema.StringAttribute{
+							Computed:    true,
+							Description: "Day of the forecast, as YYYY-MM-DD.",
+						},
+						"precipitation":             forecastAttribute("Forecast precipitation in inches."),
+						"precipitation_probability": forecastAttribute("Chance of precipitation in percent."),
+						"temperature_high":          forecastAttribute("High temperature in degrees Fahrenheit."),
+						"temperature_low":           forecastAttribute("Low temperature in degrees Fahrenheit."),
+						"wind_speed":                forecastAttribute("Wind speed in miles per hour."),
+					},
+				},
+			},
+			"upcoming_runs": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Upcoming program runs in start time order.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"program": schema.StringAttribute{
+							Computed:    true,
+							Description: "Letter of the program, A to D.",
+						},
+						"start_time": schema.StringAttribute{
+							Computed:    true,
+							Description: "When the run is due, in RFC 3339.",
+						},
+						"skip": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the controller plans to skip the run.",
+						},
+						"skip_reason": schema.StringAttribute{
+							Computed:    true,
+							Description: "Why the run is skipped, such as forecast rain, null when it is not.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *weatherDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	weather, err := d.data.api.weather(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Weather", "Could not read the device forecast: "+err.Error())
+		return
+	}
+
+	state := weatherDataSourceModel{
+		Forecast:     []forecastDayModel{},
+		UpcomingRuns: []plannedRunModel{},
+	}
+	for _, day := range weather.Forecast {
+		state.Forecast = append(state.Forecast, forecastDayModel{
+			Date:                     types.StringValue(forecastDate(day.Date)),
+			Precipitation:            types.Float64PointerValue(day.Precipitation),
+			PrecipitationProbability: types.Float64PointerValue(day.PrecipitationProbability),
+			TemperatureHigh:          types.Float64PointerValue(day.HighTemp),
+			TemperatureLow:           types.Float64PointerValue(day.LowTemp),
+			WindSpeed:                types.Float64PointerValue(day.WindSpeed),
+		})
+	}
+	sort.SliceStable(state.Forecast, func(i, j int) bool {
+		return state.Forecast[i].Date.ValueString() < state.Forecast[j].Date.ValueString()
+	})
+
+	runs := append([]apiPlannedRun(nil), weather.Upcoming...)
+	sort.SliceStable(runs, func(i, j int) bool {
+		a, _ := time.Parse(time.RFC3339, runs[i].StartTime)
+		b, _ := time.Parse(time.RFC3339, runs[j].StartTime)
+		return a.Before(b)
+	})
+	for _, run := range runs {
+		reason := types.StringNull()
+		if run.Skip {
+			reason = optionalString(run.SkipReason)
+		}
+		state.UpcomingRuns = append(state.UpcomingRuns, plannedRunModel{
+			Program:    types.StringValue(strings.ToUpper(run.Program)),
+			StartTime:  types.StringValue(run.StartTime),
+			Skip:       types.BoolValue(run.Skip),
+			SkipReason: reason,
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// forecastDate returns the YYYY-MM-DD day of a forecast date, which the API
+// may give as a full timestamp.
+func forecastDate(date string) string {
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t.Format(time.DateOnly)
+	}
+	return date
+}
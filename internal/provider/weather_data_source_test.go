@@ -0,0 +1,42 @@
+package provider
+
+import "testing"
+
+func TestWeatherDataSource(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/weather_forecasts/abc": `{
+			"forecast":[
+				{"date":"2024-06-02T00:00:00-07:00","precip":0.4,"precip_probability":80,"high_temp":68,"low_temp":52,"wind_speed":12},
+				{"date":"2024-06-01","high_temp":75,"low_temp":55}
+			],
+			"upcoming_runs":[
+				{"program":"a","start_time":"2024-06-02T05:00:00-07:00","skip":true,"skip_reason":"forecast_rain"},
+				{"program":"b","start_time":"2024-06-01T21:00:00-07:00","skip_reason":"stale"}
+			]
+		}`,
+	})
+	ds := &weatherDataSource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+
+	var got weatherDataSourceModel
+	readDataSource(t, ds, &weatherDataSourceModel{}, &got)
+
+	if len(got.Forecast) != 2 {
+		t.Fatalf("got forecast %+v", got.Forecast)
+	}
+	if today := got.Forecast[0]; today.Date.ValueString() != "2024-06-01" || !today.Precipitation.IsNull() || today.TemperatureHigh.ValueFloat64() != 75 {
+		t.Errorf("got %+v", today)
+	}
+	if tomorrow := got.Forecast[1]; tomorrow.Date.ValueString() != "2024-06-02" || tomorrow.Precipitation.ValueFloat64() != 0.4 || tomorrow.WindSpeed.ValueFloat64() != 12 {
+		t.Errorf("got %+v", tomorrow)
+	}
+
+	if len(got.UpcomingRuns) != 2 {
+		t.Fatalf("got runs %+v", got.UpcomingRuns)
+	}
+	if run := got.UpcomingRuns[0]; run.Program.ValueString() != "B" || run.Skip.ValueBool() || !run.SkipReason.IsNull() {
+		t.Errorf("got %+v", run)
+	}
+	if run := got.UpcomingRuns[1]; run.Program.ValueString() != "A" || !run.Skip.ValueBool() || run.SkipReason.ValueString() != "forecast_rain" {
+		t.Errorf("got %+v", run)
+	}
+}
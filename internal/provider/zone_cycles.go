@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// zoneCycle is one watering of a cycle and soak run, and the soak after it.
+type zoneCycle struct {
+	Minutes     int64 `tfsdk:"minutes"`
+	SoakMinutes int64 `tfsdk:"soak_minutes"`
+}
+
+var zoneCycleAttrTypes = map[string]attr.Type{
+	"minutes":      types.Int64Type,
+	"soak_minutes": types.Int64Type,
+}
+
+// cycleSchedule splits a run of minutes into the fewest cycles of at most
+// cycleMinutes, as even as whole minutes allow, with soakMinutes between
+// them. Without cycleMinutes the run is a single cycle.
+func cycleSchedule(minutes int64, cycleMinutes, soakMinutes types.Int64) []zoneCycle {
+	if cycleMinutes.IsNull() || cycleMinutes.IsUnknown() || cycleMinutes.ValueInt64() <= 0 || minutes <= cycleMinutes.ValueInt64() {
+		return []zoneCycle{{Minutes: minutes}}
+	}
+
+	n := (minutes + cycleMinutes.ValueInt64() - 1) / cycleMinutes.ValueInt64()
+	cycles := make([]zoneCycle, n)
+	for i := range cycles {
+		cycles[i].Minutes = minutes / n
+		if int64(i) < minutes%n {
+			cycles[i].Minutes++
+		}
+		if int64(i) < n-1 {
+			cycles[i].SoakMinutes = soakMinutes.ValueInt64()
+		}
+	}
+	return cycles
+}
+
+// cycleDuration returns how long the cycles take from the start of the first
+// to the end of the last, soaks included.
+func cycleDuration(cycles []zoneCycle) time.Duration {
+	var minutes int64
+	for _, c := range cycles {
+		minutes += c.Minutes + c.SoakMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// cyclesValue returns cycles as the value of the cycles attribute.
+func cyclesValue(cycles []zoneCycle) types.List {
+	elements := make([]attr.Value, 0, len(cycles))
+	for _, c := range cycles {
+		elements = append(elements, types.ObjectValueMust(zoneCycleAttrTypes, map[string]attr.Value{
+			"minutes":      types.Int64Value(c.Minutes),
+			"soak_minutes": types.Int64Value(c.SoakMinutes),
+		}))
+	}
+	return types.ListValueMust(types.ObjectType{AttrTypes: zoneCycleAttrTypes}, elements)
+}
+
+var _ resource.ConfigValidator = cycleConfigValidator{}
+
+// cycleConfigValidator warns when cycle_minutes does not split the run, as
+// the run then waters in a single cycle and soak_minutes has no effect.
+type cycleConfigValidator struct{}
+
+func (v cycleConfigValidator) Description(_ context.Context) string {
+	return "cycle_minutes should be less than minutes"
+}
+
+func (v cycleConfigValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cycleConfigValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var minutes, cycleMinutes types.Int64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("minutes"), &minutes)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cycle_minutes"), &cycleMinutes)...)
+	if resp.Diagnostics.HasError() || minutes.IsNull() || minutes.IsUnknown() || cycleMinutes.IsNull() || cycleMinutes.IsUnknown() {
+		return
+	}
+
+	if cycleMinutes.ValueInt64() >= minutes.ValueInt64() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("cycle_minutes"),
+			"Run Is A Single Cycle",
+			fmt.Sprintf("cycle_minutes (%d) is not less than minutes (%d), so the run waters in one cycle and soak_minutes has no effect.",
+				cycleMinutes.ValueInt64(), minutes.ValueInt64()),
+		)
+	}
+}
+
+// startCycle starts cycle i of a zone run and waits for the device to
+// acknowledge it, unless sub is nil as in a dry run. The guardrails are
+// checked before the first cycle, against the whole run.
+func (r *zoneResource) startCycle(ctx context.Context, sub *eventSubscription, plan zoneResourceModel, cycles []zoneCycle, i int) (deviceEvent, error) {
+	station, minutes := plan.ID.ValueInt64(), cycles[i].Minutes
+	start := startZoneCommand(r.data.client, r.data.deviceID, int(station), int(minutes))
+
+	var ack deviceEvent
+	err := r.data.command(ctx, func() error {
+		if i == 0 {
+			// Checked while holding the device, so runs started at the same
+			// time count toward each other's daily limit.
+			err := r.data.guardrails.check(ctx, r.data.api, r.data.deviceID, station, plan.Minutes.ValueInt64(), time.Now())
+			if err != nil {
+				return err
+			}
+			// Soaks stretch the run past its watering minutes.
+			if len(cycles) > 1 {
+				span := int64(cycleDuration(cycles) / time.Minute)
+				if policy := r.data.guardrails.checkQuietHours(station, span, time.Now()); policy != nil {
+					return policy
+				}
+			}
+		}
+		// A dry run sends nothing, so there is nothing to sync or wait for,
+		// and no run to count toward the daily limit.
+		if sub == nil {
+			return r.data.send(ctx, start)
+		}
+		if err := r.data.call(ctx, r.data.client.Sync); err != nil {
+			return err
+		}
+		var err error
+		ack, err = acknowledged(ctx, sub, wateringStarted(station), func() error {
+			return r.data.send(ctx, start)
+		})
+		if err == nil {
+			r.data.guardrails.started(r.data.deviceID, station, minutes, time.Now())
+		}
+		return err
+	})
+	return ack, err
+}
+
+// runCycles waits on sub for the cycles of a run whose first cycle started at
+// started, starting each later cycle once the soak before it is over. The
+// last cycle is only waited for when waitLast is set, and only then is the
+// result's Finished set. An interrupted cycle ends the run.
+func (r *zoneResource) runCycles(ctx context.Context, sub *eventSubscription, plan zoneResourceModel, cycles []zoneCycle, started time.Time, waitLast bool) (runResult, error) {
+	station := plan.ID.ValueInt64()
+	last := len(cycles) - 1
+	for i, cycle := range cycles {
+		if i > 0 {
+			ack, err := r.startCycle(ctx, sub, plan, cycles, i)
+			if err != nil {
+				return runResult{}, fmt.Errorf("starting cycle %d of %d: %w", i+1, len(cycles), err)
+			}
+			started = eventTime(ack, time.Now())
+		}
+		if i == last && !waitLast {
+			return runResult{}, nil
+		}
+
+		result, err := waitForRun(ctx, sub, station, cycle.Minutes, started)
+		if err != nil {
+			return runResult{}, fmt.Errorf("waiting for cycle %d of %d: %w", i+1, len(cycles), err)
+		}
+		if result.Interruption != "" && last > 0 {
+			result.Interruption = fmt.Sprintf("cycle %d of %d: %s", i+1, len(cycles), result.Interruption)
+		}
+		if result.Interruption != "" || i == last {
+			return result, nil
+		}
+
+		tflog.Info(ctx, "Soaking before the next cycle", map[string]interface{}{
+			"station": station,
+			"cycle":   i + 1,
+			"minutes": cycle.SoakMinutes,
+		})
+		select {
+		case <-time.After(time.Duration(cycle.SoakMinutes) * time.Minute):
+		case <-ctx.Done():
+			return runResult{}, fmt.Errorf("soaking after cycle %d of %d: %w", i+1, len(cycles), ctx.Err())
+		}
+	}
+	return runResult{}, nil
+}
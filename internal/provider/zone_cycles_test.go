@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCycleSchedule(t *testing.T) {
+	tests := []struct {
+		name          string
+		minutes       int64
+		cycle, soak   types.Int64
+		want          []zoneCycle
+		totalDuration time.Duration
+	}{
+		{
+			name:    "no cycles",
+			minutes: 25, cycle: types.Int64Null(), soak: types.Int64Null(),
+			want:          []zoneCycle{{Minutes: 25}},
+			totalDuration: 25 * time.Minute,
+		},
+		{
+			name:    "shorter than a cycle",
+			minutes: 8, cycle: types.Int64Value(10), soak: types.Int64Value(30),
+			want:          []zoneCycle{{Minutes: 8}},
+			totalDuration: 8 * time.Minute,
+		},
+		{
+			name:    "even split",
+			minutes: 25, cycle: types.Int64Value(10), soak: types.Int64Value(30),
+			want:          []zoneCycle{{Minutes: 9, SoakMinutes: 30}, {Minutes: 8, SoakMinutes: 30}, {Minutes: 8}},
+			totalDuration: 85 * time.Minute,
+		},
+		{
+			name:    "exact cycles",
+			minutes: 20, cycle: types.Int64Value(10), soak: types.Int64Value(15),
+			want:          []zoneCycle{{Minutes: 10, SoakMinutes: 15}, {Minutes: 10}},
+			totalDuration: 35 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cycleSchedule(tt.minutes, tt.cycle, tt.soak)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if d := cycleDuration(got); d != tt.totalDuration {
+				t.Errorf("got duration %s, want %s", d, tt.totalDuration)
+			}
+		})
+	}
+}
+
+func TestZoneConfigValidators(t *testing.T) {
+	ctx := context.Background()
+	r := &zoneResource{typeName: "_zone_run"}
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	tests := []struct {
+		name        string
+		cycle, soak types.Int64
+		wantError   bool
+		wantWarning bool
+	}{
+		{name: "no cycles", cycle: types.Int64Null(), soak: types.Int64Null()},
+		{name: "cycles", cycle: types.Int64Value(8), soak: types.Int64Value(20)},
+		{name: "cycle without soak", cycle: types.Int64Value(8), soak: types.Int64Null(), wantError: true},
+		{name: "soak without cycle", cycle: types.Int64Null(), soak: types.Int64Value(20), wantError: true},
+		{name: "single cycle", cycle: types.Int64Value(30), soak: types.Int64Value(20), wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := zoneResourceModel{
+				ID:           types.Int64Value(3),
+				Minutes:      types.Int64Value(24),
+				CycleMinutes: tt.cycle,
+				SoakMinutes:  tt.soak,
+				Cycles:       types.ListNull(types.ObjectType{AttrTypes: zoneCycleAttrTypes}),
+				Timeouts:     zoneTimeoutsNull(),
+			}
+			raw := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			if diags := raw.Set(ctx, model); diags.HasError() {
+				t.Fatalf("building config: %v", diags)
+			}
+
+			resp := &resource.ValidateConfigResponse{}
+			req := resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw.Raw}}
+			for _, v := range r.ConfigValidators(ctx) {
+				v.ValidateResource(ctx, req, resp)
+			}
+			if resp.Diagnostics.HasError() != tt.wantError || (resp.Diagnostics.WarningsCount() > 0) != tt.wantWarning {
+				t.Errorf("got %v", resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &zoneGroupResource{}
+	_ resource.ResourceWithConfigure  = &zoneGroupResource{}
+	_ resource.ResourceWithModifyPlan = &zoneGroupResource{}
+)
+
+// defaultZoneGroupTimeout bounds checking the group's stations against the
+// account when the timeouts block leaves it out.
+const defaultZoneGroupTimeout = 2 * time.Minute
+
+var zoneGroupDeviceAttrTypes = map[string]attr.Type{
+	"run_times":     types.ListType{ElemType: types.ObjectType{AttrTypes: runTimeAttrTypes}},
+	"total_minutes": types.Int64Type,
+}
+
+// NewZoneGroupResource returns the resource naming a set of zones that are
+// run together.
+func NewZoneGroupResource() resource.Resource {
+	return &zoneGroupResource{}
+}
+
+// zoneGroupResource is the resource implementation. B-hyve has no zone
+// groups, so a group lives only in Terraform state. Its stations are checked
+// against the account whenever it is applied or refreshed.
+type zoneGroupResource struct {
+	data *bhyveProviderData
+}
+
+type zoneGroupResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	Name         types.String   `tfsdk:"name"`
+	Zones        types.List     `tfsdk:"zones"`
+	Devices      types.Map      `tfsdk:"devices"`
+	TotalMinutes types.Int64    `tfsdk:"total_minutes"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// zoneGroupMemberModel is a zone of a group. A null DeviceID is the device
+// the provider is configured for.
+type zoneGroupMemberModel struct {
+	DeviceID types.String `tfsdk:"device_id"`
+	Station  types.Int64  `tfsdk:"station"`
+	Minutes  types.Int64  `tfsdk:"minutes"`
+}
+
+// zoneGroupDeviceModel is the part of a group on one device, in the shape
+// of a program's run_times.
+type zoneGroupDeviceModel struct {
+	RunTimes     []runTimeModel `tfsdk:"run_times"`
+	TotalMinutes int64          `tfsdk:"total_minutes"`
+}
+
+// Metadata returns the resource type name.
+func (r *zoneGroupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone_group"
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *zoneGroupResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.data = data
+}
+
+// Schema defines the schema for the resource.
+func (r *zoneGroupResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Names a set of zones, possibly on several devices of the account, with how long each waters. " +
+			"B-hyve has no zone groups, so the group only lives in Terraform state, and its stations are checked " +
+			"against the account on apply and refresh. Reference it from bhyve_zone_group_run to run the whole group, " +
+			"or pass the run_times of its devices to the provider functions as a program.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the group.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the group, such as \"front lawn\".",
+				Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"zones": schema.ListNestedAttribute{
+				Required:    true,
+				Description: "Zones of the group, in the order they run.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					uniqueStationsValidator{},
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: zoneGroupMemberAttributes(),
+				},
+			},
+			"devices": schema.MapNestedAttribute{
+				Computed:    true,
+				Description: "The zones of the group on each device, keyed by device ID.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"run_times": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.ObjectType{AttrTypes: runTimeAttrTypes},
+							Description: "Stations of the device and their minutes, in the shape of a program's run_times.",
+						},
+						"total_minutes": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Minutes the device waters for when the group runs.",
+						},
+					},
+				},
+			},
+			"total_minutes": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Minutes the whole group waters for.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create:            true,
+				Read:              true,
+				Update:            true,
+				Delete:            true,
+				DeleteDescription: stateOnlyTimeout("Deleting a zone group"),
+			}),
+		},
+	}
+}
+
+// zoneGroupMemberAttributes are the attributes of a zone of a group, which
+// bhyve_zone_group_run takes as they are.
+func zoneGroupMemberAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"device_id": schema.StringAttribute{
+			Optional:    true,
+			Description: "Device of the zone. Defaults to the device the provider is configured for.",
+			Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+		},
+		"station": schema.Int64Attribute{
+			Required:    true,
+			Description: "Station number of the zone.",
+			Validators:  []validator.Int64{int64validator.Between(1, maxStations)},
+		},
+		"minutes": schema.Int64Attribute{
+			Required:    true,
+			Description: "Minutes the zone waters for when the group runs.",
+			Validators:  []validator.Int64{int64validator.Between(1, maxRunMinutes)},
+		},
+	}
+}
+
+// ModifyPlan adds the zones of each device and the group's total to the plan.
+func (r *zoneGroupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan zoneGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Defaulted device IDs are only known once the provider is configured.
+	if r.data == nil || plan.Zones.IsUnknown() {
+		return
+	}
+	var zones []zoneGroupMemberModel
+	resp.Diagnostics.Append(plan.Zones.ElementsAs(ctx, &zones, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, zone := range zones {
+		if zone.DeviceID.IsUnknown() || zone.Station.IsUnknown() || zone.Minutes.IsUnknown() {
+			return
+		}
+	}
+
+	var diags diag.Diagnostics
+	plan.Devices, plan.TotalMinutes, diags = r.group(ctx, zones)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *zoneGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "create a zone group") {
+		return
+	}
+
+	var plan zoneGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultZoneGroupTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Read refreshes the Terraform state with the latest data. Zones that are no
+// longer on the account are reported as warnings, so the group can be fixed
+// rather than silently dropped.
+func (r *zoneGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state zoneGroupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultZoneGroupTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var zones []zoneGroupMemberModel
+	resp.Diagnostics.Append(state.Zones.ElementsAs(ctx, &zones, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, d := range r.checkStations(ctx, zones) {
+		resp.Diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *zoneGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "update a zone group") {
+		return
+	}
+
+	var plan zoneGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultZoneGroupTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+// Nothing on the devices belongs to the group.
+func (r *zoneGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	r.data.denyWrite(&resp.Diagnostics, "delete a zone group")
+}
+
+// apply checks the planned zones against the account and fills in the
+// computed attributes of plan.
+func (r *zoneGroupResource) apply(ctx context.Context, plan *zoneGroupResourceModel) diag.Diagnostics {
+	var zones []zoneGroupMemberModel
+	diags := plan.Zones.ElementsAs(ctx, &zones, false)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(r.checkStations(ctx, zones)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	var groupDiags diag.Diagnostics
+	plan.ID = plan.Name
+	plan.Devices, plan.TotalMinutes, groupDiags = r.group(ctx, zones)
+	diags.Append(groupDiags...)
+	return diags
+}
+
+// device returns the device of the zone, which is defaultDevice when the
+// zone names none.
+func (z zoneGroupMemberModel) device(defaultDevice string) string {
+	if z.DeviceID.IsNull() {
+		return defaultDevice
+	}
+	return z.DeviceID.ValueString()
+}
+
+// groupRunTimes splits zones by device. The devices are returned in the order
+// their first zone is listed, and their run times in the order listed.
+func groupRunTimes(defaultDevice string, zones []zoneGroupMemberModel) ([]string, map[string][]runTimeModel) {
+	var order []string
+	runTimes := map[string][]runTimeModel{}
+	for _, zone := range zones {
+		id := zone.device(defaultDevice)
+		if _, ok := runTimes[id]; !ok {
+			order = append(order, id)
+		}
+		runTimes[id] = append(runTimes[id], runTimeModel{Station: zone.Station.ValueInt64(), Minutes: zone.Minutes.ValueInt64()})
+	}
+	return order, runTimes
+}
+
+// group splits zones by device.
+func (r *zoneGroupResource) group(ctx context.Context, zones []zoneGroupMemberModel) (types.Map, types.Int64, diag.Diagnostics) {
+	_, runTimes := groupRunTimes(r.data.deviceID, zones)
+	devices := map[string]zoneGroupDeviceModel{}
+	var total int64
+	for id, runs := range runTimes {
+		device := zoneGroupDeviceModel{RunTimes: runs}
+		for _, run := range runs {
+			device.TotalMinutes += run.Minutes
+		}
+		devices[id] = device
+		total += device.TotalMinutes
+	}
+
+	value, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: zoneGroupDeviceAttrTypes}, devices)
+	return value, types.Int64Value(total), diags
+}
+
+// checkStations returns an error for each zone whose device or station is
+// not on the account, or that is listed twice. Each device is read once.
+func (r *zoneGroupResource) checkStations(ctx context.Context, zones []zoneGroupMemberModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	devices := map[string]*apiDevice{}
+	seen := map[string]map[int64]bool{}
+	for i, zone := range zones {
+		id := zone.device(r.data.deviceID)
+		zonePath := path.Root("zones").AtListIndex(i)
+		// A defaulted device can only be compared with the others here.
+		if seen[id] == nil {
+			seen[id] = map[int64]bool{}
+		}
+		if seen[id][zone.Station.ValueInt64()] {
+			diags.AddAttributeError(zonePath.AtName("station"), "Duplicate Station",
+				fmt.Sprintf("Station %d of device %s is listed more than once.", zone.Station.ValueInt64(), id))
+			continue
+		}
+		seen[id][zone.Station.ValueInt64()] = true
+
+		device, read := devices[id]
+		if !read {
+			var err error
+			device, err = r.data.api.device(ctx, id)
+			switch {
+			case isNotFound(err):
+				device = nil
+			case err != nil:
+				diags.AddError("Error Reading Device", fmt.Sprintf("Could not read device %s: %s", id, err))
+				return diags
+			}
+			devices[id] = device
+		}
+
+		switch {
+		case device == nil:
+			diags.AddAttributeError(zonePath.AtName("device_id"), "Device Not Found",
+				fmt.Sprintf("Device %s is not on the account.", id))
+		case !device.hasStation(zone.Station.ValueInt64()):
+			diags.AddAttributeError(zonePath.AtName("station"), "Station Not On Device",
+				fmt.Sprintf("Device %s has no station %d.", id, zone.Station.ValueInt64()))
+		}
+	}
+	return diags
+}
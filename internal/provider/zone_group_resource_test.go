@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestZoneGroup(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc": `{"id":"abc","num_stations":4}`,
+		"/devices/def": `{"id":"def","zones":[{"station":1},{"station":3}]}`,
+	})
+	r := &zoneGroupResource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+	ctx := context.Background()
+
+	zone := func(deviceID types.String, station, minutes int64) zoneGroupMemberModel {
+		return zoneGroupMemberModel{DeviceID: deviceID, Station: types.Int64Value(station), Minutes: types.Int64Value(minutes)}
+	}
+	zones := []zoneGroupMemberModel{
+		zone(types.StringNull(), 2, 10),
+		zone(types.StringValue("def"), 1, 15),
+		zone(types.StringValue("abc"), 4, 5),
+	}
+	if diags := r.checkStations(ctx, zones); diags.HasError() {
+		t.Fatalf("got errors %v", diags)
+	}
+
+	devicesValue, total, diags := r.group(ctx, zones)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	var devices map[string]zoneGroupDeviceModel
+	if diags := devicesValue.ElementsAs(ctx, &devices, false); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if total.ValueInt64() != 30 || len(devices) != 2 || devices["abc"].TotalMinutes != 15 ||
+		len(devices["abc"].RunTimes) != 2 || devices["abc"].RunTimes[1] != (runTimeModel{Station: 4, Minutes: 5}) ||
+		devices["def"].TotalMinutes != 15 {
+		t.Errorf("got devices %+v, total %v", devices, total)
+	}
+
+	for name, tc := range map[string]struct {
+		zone    zoneGroupMemberModel
+		summary string
+	}{
+		"missing station": {zone(types.StringValue("def"), 2, 10), "Station Not On Device"},
+		"past count":      {zone(types.StringNull(), 5, 10), "Station Not On Device"},
+		"missing device":  {zone(types.StringValue("ghi"), 1, 10), "Device Not Found"},
+		"defaulted twice": {zone(types.StringValue("abc"), 2, 10), "Duplicate Station"},
+	} {
+		diags := r.checkStations(ctx, append(zones[:len(zones):len(zones)], tc.zone))
+		if !diags.HasError() || diags[0].Summary() != tc.summary {
+			t.Errorf("%s: got %v, want %q", name, diags, tc.summary)
+		}
+	}
+}
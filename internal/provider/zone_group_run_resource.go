@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &zoneGroupRunResource{}
+	_ resource.ResourceWithConfigure = &zoneGroupRunResource{}
+)
+
+// defaultZoneGroupRunTimeout bounds starting every device of a group when the
+// timeouts block leaves it out. Hose timers behind a bridge can take over a
+// minute to acknowledge a command.
+const defaultZoneGroupRunTimeout = 5 * time.Minute
+
+// zoneGroupStopTimeout bounds stopping the devices already started when a
+// later device of the group fails, which happens after the create timeout
+// may have run out.
+const zoneGroupStopTimeout = 30 * time.Second
+
+// NewZoneGroupRunResource returns the resource running the zones of a group
+// as one unit.
+func NewZoneGroupRunResource() resource.Resource {
+	return &zoneGroupRunResource{}
+}
+
+// zoneGroupRunResource is the resource implementation. Each device of the
+// group is sent its stations in a single manual run, which it waters one
+// after the other, while the devices water at the same time.
+type zoneGroupRunResource struct {
+	data *bhyveProviderData
+}
+
+type zoneGroupRunResourceModel struct {
+	ID        types.String   `tfsdk:"id"`
+	Group     types.String   `tfsdk:"group"`
+	Zones     types.List     `tfsdk:"zones"`
+	StartedAt types.String   `tfsdk:"started_at"`
+	EndTime   types.String   `tfsdk:"end_time"`
+	Timeouts  timeouts.Value `tfsdk:"timeouts"`
+}
+
+// Metadata returns the resource type name.
+func (r *zoneGroupRunResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone_group_run"
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *zoneGroupRunResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.data = data
+}
+
+// Schema defines the schema for the resource.
+func (r *zoneGroupRunResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Starts a manual run of every zone of a bhyve_zone_group, across all of its devices, in one apply. " +
+			"Each device waters its zones of the group one after the other, in the order listed, while the devices " +
+			"water at the same time. Set group and zones from the group, so changing the group starts a new run.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the group that was run.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group": schema.StringAttribute{
+				Required:    true,
+				Description: "ID of the bhyve_zone_group to run.",
+				Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"zones": schema.ListNestedAttribute{
+				Required:    true,
+				Description: "Zones of the group, set from its zones attribute.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					uniqueStationsValidator{},
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: zoneGroupMemberAttributes(),
+				},
+			},
+			"started_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the first device reported the run started.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"end_time": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the device with the most minutes of the group is expected to finish.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create:            true,
+				Read:              true,
+				Update:            true,
+				Delete:            true,
+				ReadDescription:   stateOnlyTimeout("Reading a zone group run"),
+				UpdateDescription: stateOnlyTimeout("Updating a zone group run"),
+				DeleteDescription: stateOnlyTimeout("Deleting a zone group run"),
+			}),
+		},
+	}
+}
+
+// Create starts the group on each of its devices. The guardrails are checked
+// for every device before any is started, so a refused device does not leave
+// the group partly running, and a device that fails to start stops the ones
+// started before it.
+func (r *zoneGroupRunResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "run a zone group") {
+		return
+	}
+
+	var plan zoneGroupRunResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultZoneGroupRunTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	var zones []zoneGroupMemberModel
+	resp.Diagnostics.Append(plan.Zones.ElementsAs(ctx, &zones, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	devices, runTimes := groupRunTimes(r.data.deviceID, zones)
+
+	now := time.Now()
+	for _, id := range devices {
+		err := r.data.guardrails.checkSequence(ctx, r.data.api, id, runTimes[id], now)
+		var policy *policyError
+		if errors.As(err, &policy) {
+			resp.Diagnostics.AddAttributeError(path.Root("zones"), policy.Summary, policy.Detail)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Running Zone Group",
+				fmt.Sprintf("Could not check the run of group %s on device %s: %s", plan.Group.ValueString(), id, err),
+			)
+			return
+		}
+	}
+
+	// Subscribe to every device before starting any, so a device that
+	// cannot be watched fails the run before any waters. A dry run sends
+	// nothing, so there is nothing to watch.
+	subs := map[string]*eventSubscription{}
+	if r.data.dryRun == nil {
+		for _, id := range devices {
+			sub, err := r.data.events.subscribe(ctx, id)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Running Zone Group",
+					fmt.Sprintf("Could not subscribe to the events of device %s: %s", id, err),
+				)
+				return
+			}
+			defer sub.Close()
+			subs[id] = sub
+		}
+	}
+
+	var started time.Time
+	var longest int64
+	var running []string
+	for _, id := range devices {
+		at, err := r.start(ctx, subs[id], id, runTimes[id])
+		if err != nil {
+			// The run is not kept in state, so the devices already started
+			// are stopped rather than left watering, and the failed device
+			// too, in case it started without reporting it.
+			detail := fmt.Sprintf("Could not start group %s on device %s: %s.", plan.Group.ValueString(), id, err)
+			if failed := r.stop(ctx, append(running, id)); len(failed) > 0 {
+				detail += fmt.Sprintf(" The group could not be stopped on %s, which may still be watering.", strings.Join(failed, ", "))
+			}
+			resp.Diagnostics.AddError("Error Running Zone Group", detail)
+			return
+		}
+		running = append(running, id)
+		if started.IsZero() || at.Before(started) {
+			started = at
+		}
+		var minutes int64
+		for _, run := range runTimes[id] {
+			minutes += run.Minutes
+		}
+		longest = max(longest, minutes)
+	}
+
+	plan.ID = plan.Group
+	plan.StartedAt = types.StringValue(started.Format(time.RFC3339))
+	plan.EndTime = types.StringValue(started.Add(time.Duration(longest) * time.Minute).Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// start starts runTimes on the device and returns when the device reported
+// on sub that it started. sub is nil in a dry run, which only records the
+// command.
+func (r *zoneGroupRunResource) start(ctx context.Context, sub *eventSubscription, deviceID string, runTimes []runTimeModel) (time.Time, error) {
+	cmd := startStationsCommand(r.data.events, deviceID, runTimes)
+	if sub == nil {
+		return time.Now(), r.data.send(ctx, cmd)
+	}
+
+	var ack deviceEvent
+	err := deviceQueues.run(ctx, deviceID, func() error {
+		var err error
+		ack, err = acknowledged(ctx, sub, wateringStarted(runTimes[0].Station), func() error {
+			return r.data.send(ctx, cmd)
+		})
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	started := eventTime(ack, time.Now())
+	at := started
+	for _, run := range runTimes {
+		r.data.guardrails.started(deviceID, run.Station, run.Minutes, at)
+		at = at.Add(time.Duration(run.Minutes) * time.Minute)
+	}
+	tflog.Info(ctx, "Started zone group on device", map[string]interface{}{
+		"device_id": deviceID,
+		"stations":  len(runTimes),
+	})
+	return started, nil
+}
+
+// stop ends the manual runs of devices and returns the devices it could not
+// stop. It has its own timeout, as the create timeout may be what ran out.
+func (r *zoneGroupRunResource) stop(ctx context.Context, devices []string) []string {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), zoneGroupStopTimeout)
+	defer cancel()
+
+	var failed []string
+	for _, id := range devices {
+		err := deviceQueues.run(ctx, id, func() error {
+			return r.data.send(ctx, stopStationsCommand(r.data.events, id))
+		})
+		if err != nil {
+			tflog.Warn(ctx, "Could not stop zone group on device", map[string]interface{}{
+				"device_id": id,
+				"error":     err.Error(),
+			})
+			failed = append(failed, id)
+		}
+	}
+	return failed
+}
+
+// Read keeps the run as it is in state. It is a record of a run that was
+// started, which nothing on the devices can change.
+func (r *zoneGroupRunResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+// Only timeouts can change in place, which needs no call to the devices.
+func (r *zoneGroupRunResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "update a zone group run") {
+		return
+	}
+
+	var plan zoneGroupRunResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success. A
+// finished or running manual run is left alone.
+func (r *zoneGroupRunResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	r.data.denyWrite(&resp.Diagnostics, "delete a zone group run")
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/time/rate"
+)
+
+// createZoneGroupRun plans a run of the front lawn group and creates it,
+// with the create timeout when it is not empty.
+func createZoneGroupRun(t *testing.T, r *zoneGroupRunResource, createTimeout string) resource.CreateResponse {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	zones, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: map[string]attr.Type{
+		"device_id": types.StringType,
+		"station":   types.Int64Type,
+		"minutes":   types.Int64Type,
+	}}, []zoneGroupMemberModel{
+		{DeviceID: types.StringNull(), Station: types.Int64Value(2), Minutes: types.Int64Value(10)},
+		{DeviceID: types.StringValue("def"), Station: types.Int64Value(1), Minutes: types.Int64Value(15)},
+		{DeviceID: types.StringValue("abc"), Station: types.Int64Value(4), Minutes: types.Int64Value(5)},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	timeoutTypes := map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	}
+	timeoutsValue := types.ObjectNull(timeoutTypes)
+	if createTimeout != "" {
+		timeoutsValue = types.ObjectValueMust(timeoutTypes, map[string]attr.Value{
+			"create": types.StringValue(createTimeout),
+			"read":   types.StringNull(),
+			"update": types.StringNull(),
+			"delete": types.StringNull(),
+		})
+	}
+	model := zoneGroupRunResourceModel{
+		ID:        types.StringUnknown(),
+		Group:     types.StringValue("front lawn"),
+		Zones:     zones,
+		StartedAt: types.StringUnknown(),
+		EndTime:   types.StringUnknown(),
+		Timeouts:  timeouts.Value{Object: timeoutsValue},
+	}
+	raw := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := raw.Set(ctx, model); diags.HasError() {
+		t.Fatalf("building plan: %v", diags)
+	}
+
+	resp := resource.CreateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw.Raw}}, &resp)
+	return resp
+}
+
+func TestZoneGroupRun(t *testing.T) {
+	f := newFakeEvents(t)
+	r := &zoneGroupRunResource{data: &bhyveProviderData{
+		deviceID:   "abc",
+		retry:      retryPolicy{maxElapsed: time.Second},
+		limiter:    rate.NewLimiter(rate.Inf, 1),
+		events:     f.hub(),
+		guardrails: &guardrails{},
+	}}
+
+	// The fake devices acknowledge each run by starting its first station.
+	commands := make(chan map[string]interface{}, 2)
+	go func() {
+		conn := <-f.conns
+		for sent := 0; sent < cap(commands); {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg["event"] != "change_mode" {
+				continue
+			}
+			commands <- msg
+			sent++
+			first := msg["stations"].([]interface{})[0].(map[string]interface{})
+			_ = conn.WriteJSON(map[string]interface{}{
+				"event":           eventWateringInProgress,
+				"device_id":       msg["device_id"],
+				"current_station": first["station"],
+			})
+		}
+	}()
+
+	resp := createZoneGroupRun(t, r, "")
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	close(commands)
+	stations := map[interface{}][]interface{}{}
+	for msg := range commands {
+		if msg["mode"] != "manual" {
+			t.Errorf("got mode %v", msg["mode"])
+		}
+		for _, s := range msg["stations"].([]interface{}) {
+			stations[msg["device_id"]] = append(stations[msg["device_id"]], s.(map[string]interface{})["station"])
+		}
+	}
+	if len(stations) != 2 || len(stations["abc"]) != 2 || stations["abc"][1] != float64(4) || len(stations["def"]) != 1 {
+		t.Errorf("got stations %v, want 2 and 4 on abc and 1 on def", stations)
+	}
+
+	var state zoneGroupRunResourceModel
+	if diags := resp.State.Get(context.Background(), &state); diags.HasError() {
+		t.Fatal(diags)
+	}
+	started, err := time.Parse(time.RFC3339, state.StartedAt.ValueString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end := started.Add(15 * time.Minute).Format(time.RFC3339); state.ID.ValueString() != "front lawn" || state.EndTime.ValueString() != end {
+		t.Errorf("got id %s and end_time %s, want front lawn and %s", state.ID, state.EndTime, end)
+	}
+
+	// The runs count toward the daily limit of their own device.
+	r.data.guardrails.mu.Lock()
+	defer r.data.guardrails.mu.Unlock()
+	if runs := r.data.guardrails.runs; len(runs) != 3 || runs[2].deviceID != "def" {
+		t.Errorf("got guarded runs %+v", runs)
+	}
+}
+
+func TestZoneGroupRunStopsStartedDevices(t *testing.T) {
+	f := newFakeEvents(t)
+	// The fake sends no keepalives, so the hub must not time out the
+	// connection while def is waited for.
+	hub := f.hub()
+	hub.idleTimeout = time.Minute
+	r := &zoneGroupRunResource{data: &bhyveProviderData{
+		deviceID:   "abc",
+		retry:      retryPolicy{maxElapsed: time.Second},
+		limiter:    rate.NewLimiter(rate.Inf, 1),
+		events:     hub,
+		guardrails: &guardrails{},
+	}}
+
+	// Device abc acknowledges its run, def never does.
+	stops := make(chan interface{}, 2)
+	go func() {
+		conn := <-f.conns
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg["event"] != "change_mode" {
+				continue
+			}
+			stations := msg["stations"].([]interface{})
+			if len(stations) == 0 {
+				stops <- msg["device_id"]
+				continue
+			}
+			if msg["device_id"] == "abc" {
+				_ = conn.WriteJSON(map[string]interface{}{
+					"event":           eventWateringInProgress,
+					"device_id":       "abc",
+					"current_station": stations[0].(map[string]interface{})["station"],
+				})
+			}
+		}
+	}()
+
+	resp := createZoneGroupRun(t, r, "1s")
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Error Running Zone Group" {
+		t.Fatalf("got %v", resp.Diagnostics)
+	}
+	if strings.Contains(resp.Diagnostics[0].Detail(), "could not be stopped") {
+		t.Errorf("got %q", resp.Diagnostics[0].Detail())
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("state was written for a group that did not start")
+	}
+
+	stopped := map[interface{}]bool{}
+	for len(stopped) < 2 {
+		select {
+		case id := <-stops:
+			stopped[id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got stops for %v, want abc and def", stopped)
+		}
+	}
+}
+
+func TestZoneGroupRunDryRun(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "commands.jsonl")
+	// The data has no events hub, so any command sent would panic.
+	r := &zoneGroupRunResource{data: &bhyveProviderData{
+		deviceID:   "abc",
+		retry:      retryPolicy{maxElapsed: time.Second},
+		limiter:    rate.NewLimiter(rate.Inf, 1),
+		dryRun:     &dryRunRecorder{path: log},
+		guardrails: &guardrails{maxZoneMinutes: 12},
+	}}
+
+	// A device refused by a guardrail stops the whole group.
+	resp := createZoneGroupRun(t, r, "")
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Run Blocked by max_zone_minutes" {
+		t.Fatalf("got %v", resp.Diagnostics)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Errorf("commands were recorded for a refused group: %v", err)
+	}
+
+	r.data.guardrails.maxZoneMinutes = 0
+	if resp := createZoneGroupRun(t, r, ""); resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"device_id":"abc"`) || !strings.Contains(lines[1], `"device_id":"def"`) {
+		t.Errorf("got recorded commands %q, want one for abc and one for def", lines)
+	}
+	if len(r.data.guardrails.runs) != 0 {
+		t.Errorf("dry run counted toward the daily limit: %v", r.data.guardrails.runs)
+	}
+}
@@ -1,164 +1,639 @@
 package provider
 
 import (
-    "context"
-		"fmt"
-		"time"
-		"strconv"
-    
-		"github.com/gillcaleb/orbit-bhyve-go-client/pkg/client"
-    "github.com/hashicorp/terraform-plugin-framework/resource"
-    "github.com/hashicorp/terraform-plugin-framework/resource/schema"
-		"github.com/hashicorp/terraform-plugin-framework/types"
-		"github.com/hashicorp/terraform-plugin-log/tflog"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-    _ resource.Resource              = &zoneResource{}
-		_ resource.ResourceWithConfigure = &zoneResource{}
+	_ resource.Resource                     = &zoneResource{}
+	_ resource.ResourceWithConfigure        = &zoneResource{}
+	_ resource.ResourceWithModifyPlan       = &zoneResource{}
+	_ resource.ResourceWithConfigValidators = &zoneResource{}
+)
+
+// Default timeouts of zone operations. Hose timers behind a bridge can take
+// over a minute to acknowledge a command.
+const (
+	defaultZoneCreateTimeout = 5 * time.Minute
+	defaultZoneReadTimeout   = 2 * time.Minute
 )
 
 // NewZoneResource is a helper function to simplify the provider implementation.
 func NewZoneResource() resource.Resource {
-    return &zoneResource{}
+	return &zoneResource{typeName: "_zone"}
+}
+
+// NewZoneRunResource returns the manual run resource. It shares the zone
+// implementation and accepts state moved from bhyve_zone.
+func NewZoneRunResource() resource.Resource {
+	return &zoneResource{typeName: "_zone_run"}
 }
 
 // zoneResource is the resource implementation.
-type zoneResource struct{
-	client *client.Client
+type zoneResource struct {
+	data *bhyveProviderData
+
+	// typeName is the resource type name without the provider prefix.
+	typeName string
 }
 
 type zoneResourceModel struct {
-	ID          types.String     `tfsdk:"id"`
-	LastUpdated types.String     `tfsdk:"last_updated"`
-	Minutes     types.String     `tfsdk:"minutes"`
+	ID                 types.Int64    `tfsdk:"id"`
+	LastUpdated        types.String   `tfsdk:"last_updated"`
+	Minutes            types.Int64    `tfsdk:"minutes"`
+	CycleMinutes       types.Int64    `tfsdk:"cycle_minutes"`
+	SoakMinutes        types.Int64    `tfsdk:"soak_minutes"`
+	Cycles             types.List     `tfsdk:"cycles"`
+	TotalDuration      types.Int64    `tfsdk:"total_duration"`
+	FlowRate           types.Float64  `tfsdk:"flow_rate"`
+	TotalRuntime       types.Int64    `tfsdk:"total_runtime"`
+	ProjectedGallons   types.Float64  `tfsdk:"projected_gallons"`
+	EndTime            types.String   `tfsdk:"end_time"`
+	WaitForCompletion  types.Bool     `tfsdk:"wait_for_completion"`
+	StartedAt          types.String   `tfsdk:"started_at"`
+	FinishedAt         types.String   `tfsdk:"finished_at"`
+	InterruptionReason types.String   `tfsdk:"interruption_reason"`
+	LastWatered        types.String   `tfsdk:"last_watered"`
+	LastRunMinutes     types.Float64  `tfsdk:"last_run_minutes"`
+	DefaultMinutes     types.Int64    `tfsdk:"default_minutes"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+// zoneTimeoutsNull is the value of an absent timeouts block.
+func zoneTimeoutsNull() timeouts.Value {
+	return timeouts.Value{
+		Object: types.ObjectNull(map[string]attr.Type{
+			"create": types.StringType,
+			"read":   types.StringType,
+			"update": types.StringType,
+			"delete": types.StringType,
+		}),
+	}
 }
 
 // Metadata returns the resource type name.
 func (r *zoneResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-    resp.TypeName = req.ProviderTypeName + "_zone"
+	resp.TypeName = req.ProviderTypeName + r.typeName
 }
 
 // Configure adds the provider configured client to the resource.
 func (r *zoneResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-  // Add a nil check when handling ProviderData because Terraform
-  // sets that data after it calls the ConfigureProvider RPC.
-  if req.ProviderData == nil {
-        return
-    }
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
 
-    client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*bhyveProviderData)
 
-    if !ok {
-        resp.Diagnostics.AddError(
-            "Unexpected Data Source Configure Type",
-            fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
-        )
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
 
-        return
-    }
+		return
+	}
 
-    r.client = client
+	r.data = data
 }
 
 // Schema defines the schema for the resource.
-func (r *zoneResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *zoneResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	var deprecation string
+	if r.typeName == "_zone" {
+		deprecation = "Use bhyve_zone_run instead. Existing runs can be migrated with a moved block."
+	}
+
 	resp.Schema = schema.Schema{
-			Attributes: map[string]schema.Attribute{
-					"id": schema.StringAttribute{
-							Required: true,
-					},
-					"last_updated": schema.StringAttribute{
-							Computed: true,
+		Version:            2,
+		DeprecationMessage: deprecation,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Required:    true,
+				Description: "Station number of the zone. Plans that start a run check the station is one of the zones of the device.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, maxStations),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"last_updated": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the device acknowledged the run, in RFC 3339 format.",
+			},
+			"minutes": schema.Int64Attribute{
+				Required:    true,
+				Description: "Minutes to water the zone for.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, maxRunMinutes),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"cycle_minutes": schema.Int64Attribute{
+				Optional: true,
+				Description: "Split the run into cycles of at most this many minutes, with soak_minutes between them, " +
+					"so water soaks into clay soil and slopes instead of running off. The device takes manual runs " +
+					"as a single watering, so the provider starts each cycle itself and create waits for all but " +
+					"the last. Set together with soak_minutes.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, maxRunMinutes),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"soak_minutes": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Minutes to pause between cycles. Set together with cycle_minutes.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, maxRunMinutes),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"cycles": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The cycles the run is split into, in order. A run without cycle_minutes is a single cycle.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"minutes": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Minutes the cycle waters for.",
+						},
+						"soak_minutes": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Minutes to soak after the cycle, 0 after the last.",
+						},
 					},
-					"minutes": schema.StringAttribute{
-						  Required: true,
-					},					
+				},
+			},
+			"flow_rate": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Flow rate of the zone in gallons per minute, used to project water use.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"total_runtime": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Total minutes the zone waters for.",
+			},
+			"total_duration": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Minutes from the start of the run to its end, soaks included.",
+			},
+			"projected_gallons": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Projected water use of the run in gallons. Null when flow_rate is not set.",
 			},
+			"end_time": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the run is expected to finish.",
+			},
+			"wait_for_completion": schema.BoolAttribute{
+				Optional: true,
+				Description: "Wait, up to the create timeout, for the device to report the run finished. " +
+					"The create timeout defaults to the total duration plus five minutes when this is set.",
+			},
+			"started_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the device reported the run started.",
+			},
+			"finished_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the device reported the run finished. Only recorded with wait_for_completion.",
+			},
+			"interruption_reason": schema.StringAttribute{
+				Computed:    true,
+				Description: "Why the run ended early, null when it ran in full. Only recorded with wait_for_completion.",
+			},
+			"last_watered": schema.StringAttribute{
+				Computed:    true,
+				Description: "Time the station last watered according to the device history, in RFC 3339 format.",
+			},
+			"last_run_minutes": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Length in minutes of the station's last watering according to the device history.",
+			},
+			"default_minutes": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Default runtime in minutes of a manual run configured on the device.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create:            true,
+				Read:              true,
+				Update:            true,
+				Delete:            true,
+				UpdateDescription: stateOnlyTimeout("Updating a zone run"),
+				DeleteDescription: stateOnlyTimeout("Deleting a zone run"),
+			}),
+		},
 	}
 }
 
+// ConfigValidators returns the checks that span several attributes.
+func (r *zoneResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.RequiredTogether(
+			path.MatchRoot("cycle_minutes"),
+			path.MatchRoot("soak_minutes"),
+		),
+		cycleConfigValidator{},
+	}
+}
+
+// ModifyPlan adds the runtime and projected water use of the run to the plan
+// and warns when the change raises the water use of the run by more than
+// run_water_warning_gallons. A manual run happens once, so its water use is
+// compared per run rather than per week.
+func (r *zoneResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to project when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan zoneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The provider policies only apply to plans that start a run, so
+	// tightening them does not fail plans for runs already started.
+	starts := req.State.Raw.IsNull()
+	var prior float64
+	if !starts {
+		var state zoneResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// The run is not restarted in place, so it still ends when planned.
+		plan.LastUpdated = state.LastUpdated
+		plan.EndTime = state.EndTime
+		plan.StartedAt = state.StartedAt
+		plan.FinishedAt = state.FinishedAt
+		plan.InterruptionReason = state.InterruptionReason
+		plan.LastWatered = state.LastWatered
+		plan.LastRunMinutes = state.LastRunMinutes
+		plan.DefaultMinutes = state.DefaultMinutes
+		prior = state.ProjectedGallons.ValueFloat64()
+		starts = !plan.ID.Equal(state.ID) || !plan.Minutes.Equal(state.Minutes)
+	}
+
+	if r.data != nil && starts && !plan.Minutes.IsUnknown() {
+		if policy := r.data.guardrails.checkMinutes(plan.ID.ValueInt64(), plan.Minutes.ValueInt64()); policy != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("minutes"), policy.Summary, policy.Detail)
+			return
+		}
+	}
+
+	if r.data != nil && starts && r.data.stationCount > 0 && plan.ID.ValueInt64() > r.data.stationCount {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"Station Not On Device",
+			fmt.Sprintf("Station %d does not exist, the device has %d stations as set by station_count.",
+				plan.ID.ValueInt64(), r.data.stationCount),
+		)
+		return
+	}
+
+	if r.data != nil && r.data.api != nil && starts && !plan.ID.IsUnknown() {
+		device, err := r.data.api.device(ctx, r.data.deviceID)
+		switch {
+		case isNotFound(err):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("id"),
+				"Station Not On Device",
+				fmt.Sprintf("Device %s does not exist, so station %d cannot be run.", r.data.deviceID, plan.ID.ValueInt64()),
+			)
+			return
+		case err != nil:
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("id"),
+				"Station Not Checked",
+				fmt.Sprintf("Could not read device %s to check it has station %d: %s", r.data.deviceID, plan.ID.ValueInt64(), err),
+			)
+		case !device.hasStation(plan.ID.ValueInt64()):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("id"),
+				"Station Not On Device",
+				fmt.Sprintf("Station %d is not one of the zones of device %s.", plan.ID.ValueInt64(), r.data.deviceID),
+			)
+			return
+		}
+	}
+
+	if plan.Minutes.IsUnknown() || plan.FlowRate.IsUnknown() || plan.CycleMinutes.IsUnknown() || plan.SoakMinutes.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	minutes := plan.Minutes.ValueInt64()
+	plan.TotalRuntime = types.Int64Value(minutes)
+	cycles := cycleSchedule(minutes, plan.CycleMinutes, plan.SoakMinutes)
+	plan.TotalDuration = types.Int64Value(int64(cycleDuration(cycles) / time.Minute))
+	plan.Cycles = cyclesValue(cycles)
+	plan.ProjectedGallons = types.Float64Null()
+	if !plan.FlowRate.IsNull() {
+		plan.ProjectedGallons = types.Float64Value(plan.FlowRate.ValueFloat64() * float64(minutes))
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+
+	if r.data == nil || r.data.runWaterWarningGallons <= 0 {
+		return
+	}
+	if increase := plan.ProjectedGallons.ValueFloat64() - prior; increase > r.data.runWaterWarningGallons {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("minutes"),
+			"Run Water Use Increase",
+			fmt.Sprintf("This change increases the water used by the run by %.1f gallons, more than the %.1f gallon threshold set by run_water_warning_gallons.",
+				increase, r.data.runWaterWarningGallons),
+		)
+	}
+}
 
 // Create creates the resource and sets the initial Terraform state.
 func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "start a zone run") {
+		return
+	}
+
 	// Retrieve values from plan
 	var plan zoneResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-			return
+		return
+	}
+
+	id := int(plan.ID.ValueInt64())
+	minutes := int(plan.Minutes.ValueInt64())
+	wait := plan.WaitForCompletion.ValueBool()
+	cycles := cycleSchedule(plan.Minutes.ValueInt64(), plan.CycleMinutes, plan.SoakMinutes)
+	duration := cycleDuration(cycles)
+
+	// Every cycle but the last is always waited for.
+	defaultTimeout := defaultZoneCreateTimeout
+	if wait || len(cycles) > 1 {
+		defaultTimeout += duration
+	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
-	id, err := strconv.Atoi(plan.ID.ValueString())
-  if err != nil {
+	// Check the station is on the device before anything is sent, as a run
+	// of a station that is not cannot be kept in state.
+	found, err := r.readDevice(ctx, &plan)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error converting ID",
-			"Could not convert plan.ID to int: "+err.Error(),
+			"Error Starting Zone",
+			fmt.Sprintf("Could not read station %d from the device: %s", id, err),
+		)
+		return
+	}
+	if !found {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"Zone Not Found",
+			fmt.Sprintf("Station %d is not on device %s, or the device no longer exists.", id, r.data.deviceID),
 		)
 		return
 	}
 
-	minutes, err := strconv.Atoi(plan.Minutes.ValueString())
+	dryRun := r.data.dryRun != nil
+	var sub *eventSubscription
+	if !dryRun {
+		sub, err = r.data.watch(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Starting Zone",
+				fmt.Sprintf("Could not start station %d: %s", id, err),
+			)
+			return
+		}
+		defer sub.Close()
+	}
+
+	// Create new zone run and wait for the device to start watering
+	ack, err := r.startCycle(ctx, sub, plan, cycles, 0)
+	var policy *policyError
+	if errors.As(err, &policy) {
+		resp.Diagnostics.AddAttributeError(path.Root("minutes"), policy.Summary, policy.Detail)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error converting minutes",
-			"Could not convert plan.Minutes to int: "+err.Error(),
+			"Error Starting Zone",
+			fmt.Sprintf("Could not start station %d: %s", id, err),
 		)
 		return
 	}
 
-	// Create new zone run
-	r.client.Sync()
-	r.client.StartZone(id, minutes)
-	tflog.Info(ctx, "Checking the status of the StartZone command")
-
 	// Map response body to schema and populate Computed attribute values
-	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+	started := eventTime(ack, time.Now())
+	plan.LastUpdated = types.StringValue(started.Format(time.RFC3339))
+	plan.StartedAt = types.StringValue(started.Format(time.RFC3339))
+	plan.EndTime = types.StringValue(started.Add(duration).Format(time.RFC3339))
+	plan.FinishedAt = types.StringNull()
+	plan.InterruptionReason = types.StringNull()
+
+	if dryRun {
+		// Nothing waters in a dry run, so the later cycles are recorded
+		// right away.
+		for i := 1; i < len(cycles); i++ {
+			if _, err := r.startCycle(ctx, nil, plan, cycles, i); err != nil {
+				resp.Diagnostics.AddError(
+					"Error Starting Zone",
+					fmt.Sprintf("Could not start station %d: %s", id, err),
+				)
+				return
+			}
+		}
+		if wait {
+			tflog.Info(ctx, "Dry run, not waiting for the zone run to finish")
+		}
+	} else if wait || len(cycles) > 1 {
+		tflog.Info(ctx, "Waiting for zone run to finish", map[string]interface{}{
+			"station": id,
+			"minutes": minutes,
+			"cycles":  len(cycles),
+		})
+		result, err := r.runCycles(ctx, sub, plan, cycles, started, wait)
+		if err != nil {
+			// The run did start, so record it before reporting the error.
+			resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+			detail := fmt.Sprintf("Station %d did not report finishing within the create timeout of %s: %s", id, createTimeout, err)
+			if ctx.Err() == nil {
+				detail = fmt.Sprintf("Station %d started, but its run could not be completed: %s", id, err)
+			}
+			resp.Diagnostics.AddError("Zone Run Did Not Finish", detail)
+			return
+		}
+		if !result.Finished.IsZero() {
+			plan.FinishedAt = types.StringValue(result.Finished.Format(time.RFC3339))
+		}
+		if result.Interruption != "" {
+			plan.InterruptionReason = types.StringValue(result.Interruption)
+		}
+	}
+
+	// Read back what the device reports about the station now that it ran.
+	// The run has started, so failing to do so does not fail the create,
+	// which keeps what was read before the run.
+	found, err = r.readDevice(ctx, &plan)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Error Reading Zone",
+			fmt.Sprintf("Station %d was started, but could not be read back from the device: %s", id, err),
+		)
+	} else if !found {
+		resp.Diagnostics.AddWarning(
+			"Zone Not Found",
+			fmt.Sprintf("Station %d was started, but is no longer reported by device %s.", id, r.data.deviceID),
+		)
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-			return
+		return
 	}
 }
 
 // Read refreshes the Terraform state with the latest data.
 func (r *zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state zoneResourceModel
-  diags := req.State.Get(ctx, &state)
+	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-			return
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultZoneReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-  
-	idi, err := strconv.Atoi(state.ID.ValueString())
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	// Get zone information
+	found, err := r.readDevice(ctx, &state)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error converting ID",
-			"Could not convert plan.ID to int: "+err.Error(),
+			"Error Reading Zone",
+			fmt.Sprintf("Could not read station %d: %s", state.ID.ValueInt64(), err),
 		)
 		return
 	}
-	// Get zone information
-	id := r.client.ReadZone(idi)
+	if !found {
+		tflog.Warn(ctx, "Station no longer exists on the device, removing it from state", map[string]interface{}{
+			"station": state.ID.ValueInt64(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	// Map response body to schema and populate attribute values
-	state.Minutes = types.StringValue(id)
+	// State written before cycle_minutes existed has no cycles.
+	if state.Cycles.IsNull() {
+		cycles := cycleSchedule(state.Minutes.ValueInt64(), state.CycleMinutes, state.SoakMinutes)
+		state.Cycles = cyclesValue(cycles)
+		state.TotalDuration = types.Int64Value(int64(cycleDuration(cycles) / time.Minute))
+	}
 
-	 // Set refreshed state
-	 diags = resp.State.Set(ctx, &state)
-	 resp.Diagnostics.Append(diags...)
-	 if resp.Diagnostics.HasError() {
-			 return
-	 }
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// readDevice refreshes the attributes the device reports about the station.
+// It returns false when the station, or the whole device, no longer exists.
+func (r *zoneResource) readDevice(ctx context.Context, model *zoneResourceModel) (bool, error) {
+	station := model.ID.ValueInt64()
+
+	device, err := r.data.api.device(ctx, r.data.deviceID)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !device.hasStation(station) {
+		return false, nil
+	}
+
+	events, err := r.data.api.wateringEvents(ctx, r.data.deviceID)
+	if err != nil {
+		return false, err
+	}
+
+	model.DefaultMinutes = types.Int64Null()
+	if device.ManualPresetRuntimeSec > 0 {
+		model.DefaultMinutes = types.Int64Value(device.ManualPresetRuntimeSec / 60)
+	}
+	model.LastWatered = types.StringNull()
+	model.LastRunMinutes = types.Float64Null()
+	if watered, run, ok := lastRun(events, station); ok {
+		model.LastWatered = types.StringValue(watered.Format(time.RFC3339))
+		model.LastRunMinutes = types.Float64Value(run.RunTime)
+	}
+	return true, nil
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
+// Only flow_rate, wait_for_completion and timeouts can change in place, which
+// need no call to the device.
 func (r *zoneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.data.denyWrite(&resp.Diagnostics, "update a zone run") {
+		return
+	}
+
+	var plan zoneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
+// Delete deletes the resource and removes the Terraform state on success. A
+// finished or running manual run is left alone.
 func (r *zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	r.data.denyWrite(&resp.Diagnostics, "delete a zone run")
 }
@@ -0,0 +1,332 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithUpgradeState = &zoneResource{}
+	_ resource.ResourceWithMoveState    = &zoneResource{}
+)
+
+// Namespace and type of the provider in its source address, which state
+// moved from another resource type records.
+const (
+	providerNamespace = "gillcaleb"
+	providerType      = "bhyve"
+)
+
+// zoneResourceModelV0 is the zone state before schema version 1, when the
+// station and runtime were stored as strings.
+type zoneResourceModelV0 struct {
+	ID          types.String `tfsdk:"id"`
+	LastUpdated types.String `tfsdk:"last_updated"`
+	Minutes     types.String `tfsdk:"minutes"`
+}
+
+var zoneSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Required: true,
+		},
+		"last_updated": schema.StringAttribute{
+			Computed: true,
+		},
+		"minutes": schema.StringAttribute{
+			Required: true,
+		},
+	},
+}
+
+// zoneResourceModelV1 is the zone state of schema version 1, before the
+// device readings were added and last_updated moved to RFC 3339.
+type zoneResourceModelV1 struct {
+	ID                 types.Int64   `tfsdk:"id"`
+	LastUpdated        types.String  `tfsdk:"last_updated"`
+	Minutes            types.Int64   `tfsdk:"minutes"`
+	FlowRate           types.Float64 `tfsdk:"flow_rate"`
+	TotalRuntime       types.Int64   `tfsdk:"total_runtime"`
+	ProjectedGallons   types.Float64 `tfsdk:"projected_gallons"`
+	EndTime            types.String  `tfsdk:"end_time"`
+	WaitForCompletion  types.Bool    `tfsdk:"wait_for_completion"`
+	StartedAt          types.String  `tfsdk:"started_at"`
+	FinishedAt         types.String  `tfsdk:"finished_at"`
+	InterruptionReason types.String  `tfsdk:"interruption_reason"`
+	Timeouts           types.Object  `tfsdk:"timeouts"`
+}
+
+var zoneSchemaV1 = schema.Schema{
+	Version: 1,
+	Attributes: map[string]schema.Attribute{
+		"id": schema.Int64Attribute{
+			Required: true,
+		},
+		"last_updated": schema.StringAttribute{
+			Computed: true,
+		},
+		"minutes": schema.Int64Attribute{
+			Required: true,
+		},
+		"flow_rate": schema.Float64Attribute{
+			Optional: true,
+		},
+		"total_runtime": schema.Int64Attribute{
+			Computed: true,
+		},
+		"projected_gallons": schema.Float64Attribute{
+			Computed: true,
+		},
+		"end_time": schema.StringAttribute{
+			Computed: true,
+		},
+		"wait_for_completion": schema.BoolAttribute{
+			Optional: true,
+		},
+		"started_at": schema.StringAttribute{
+			Computed: true,
+		},
+		"finished_at": schema.StringAttribute{
+			Computed: true,
+		},
+		"interruption_reason": schema.StringAttribute{
+			Computed: true,
+		},
+	},
+	Blocks: map[string]schema.Block{
+		"timeouts": schema.SingleNestedBlock{
+			Attributes: map[string]schema.Attribute{
+				"create": schema.StringAttribute{Optional: true},
+				"read":   schema.StringAttribute{Optional: true},
+				"update": schema.StringAttribute{Optional: true},
+				"delete": schema.StringAttribute{Optional: true},
+			},
+		},
+	},
+}
+
+// UpgradeState converts state written by earlier schema versions.
+func (r *zoneResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &zoneSchemaV0,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior zoneResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				state, diags := upgradeZoneStateV0(ctx, prior)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+			},
+		},
+		1: {
+			PriorSchema: &zoneSchemaV1,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior zoneResourceModelV1
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, upgradeZoneStateV1(ctx, prior))...)
+			},
+		},
+	}
+}
+
+// MoveState accepts bhyve_zone state of this provider, of any schema
+// version, so that a moved block can migrate a run to bhyve_zone_run.
+func (r *zoneResource) MoveState(ctx context.Context) []resource.StateMover {
+	var current resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &current)
+
+	return []resource.StateMover{
+		{
+			SourceSchema: &zoneSchemaV0,
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				if !movableZoneState(req, 0) {
+					return
+				}
+
+				var prior zoneResourceModelV0
+				resp.Diagnostics.Append(req.SourceState.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				state, diags := upgradeZoneStateV0(ctx, prior)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, state)...)
+			},
+		},
+		{
+			SourceSchema: &zoneSchemaV1,
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				if !movableZoneState(req, 1) {
+					return
+				}
+
+				var prior zoneResourceModelV1
+				resp.Diagnostics.Append(req.SourceState.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, upgradeZoneStateV1(ctx, prior))...)
+			},
+		},
+		{
+			SourceSchema: &current.Schema,
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				if !movableZoneState(req, current.Schema.Version) {
+					return
+				}
+
+				var state zoneResourceModel
+				resp.Diagnostics.Append(req.SourceState.Get(ctx, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, state)...)
+			},
+		},
+	}
+}
+
+// movableZoneState reports whether req moves bhyve_zone state of the given
+// schema version from this provider. A bhyve_zone of another provider is
+// left for Terraform to reject.
+func movableZoneState(req resource.MoveStateRequest, version int64) bool {
+	if req.SourceTypeName != "bhyve_zone" || req.SourceSchemaVersion != version || req.SourceState == nil {
+		return false
+	}
+	// The address is hostname/namespace/type, and the hostname depends on
+	// where the provider was installed from.
+	parts := strings.Split(req.SourceProviderAddress, "/")
+	return len(parts) == 3 && parts[1] == providerNamespace && parts[2] == providerType
+}
+
+// upgradeZoneStateV0 converts a version 0 zone state to the current model.
+// The end of the run is recovered from the time it was started.
+func upgradeZoneStateV0(ctx context.Context, prior zoneResourceModelV0) (zoneResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	id, err := strconv.ParseInt(prior.ID.ValueString(), 10, 64)
+	if err != nil {
+		diags.AddError(
+			"Error Upgrading Zone State",
+			fmt.Sprintf("Could not convert id %q to a station number: %s", prior.ID.ValueString(), err),
+		)
+	}
+
+	minutes, err := strconv.ParseInt(prior.Minutes.ValueString(), 10, 64)
+	if err != nil {
+		diags.AddError(
+			"Error Upgrading Zone State",
+			fmt.Sprintf("Could not convert minutes %q to a number: %s", prior.Minutes.ValueString(), err),
+		)
+	}
+
+	if diags.HasError() {
+		return zoneResourceModel{}, diags
+	}
+
+	prior1 := zoneResourceModelV1{
+		ID:                 types.Int64Value(id),
+		LastUpdated:        prior.LastUpdated,
+		Minutes:            types.Int64Value(minutes),
+		FlowRate:           types.Float64Null(),
+		TotalRuntime:       types.Int64Value(minutes),
+		ProjectedGallons:   types.Float64Null(),
+		EndTime:            types.StringNull(),
+		WaitForCompletion:  types.BoolNull(),
+		StartedAt:          types.StringNull(),
+		FinishedAt:         types.StringNull(),
+		InterruptionReason: types.StringNull(),
+		Timeouts:           types.ObjectNull(nil),
+	}
+	if started, err := time.Parse(time.RFC850, prior.LastUpdated.ValueString()); err == nil {
+		prior1.EndTime = types.StringValue(started.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339))
+	}
+
+	return upgradeZoneStateV1(ctx, prior1), diags
+}
+
+// upgradeZoneStateV1 converts a version 1 zone state to the current model.
+// A run of version 1 waters in a single cycle, and the device readings are
+// left for the next refresh.
+func upgradeZoneStateV1(ctx context.Context, prior zoneResourceModelV1) zoneResourceModel {
+	minutes := prior.Minutes.ValueInt64()
+	return zoneResourceModel{
+		ID:                 prior.ID,
+		LastUpdated:        rfc3339LastUpdated(prior.LastUpdated),
+		Minutes:            prior.Minutes,
+		CycleMinutes:       types.Int64Null(),
+		SoakMinutes:        types.Int64Null(),
+		Cycles:             cyclesValue([]zoneCycle{{Minutes: minutes}}),
+		TotalDuration:      types.Int64Value(minutes),
+		FlowRate:           prior.FlowRate,
+		TotalRuntime:       prior.TotalRuntime,
+		ProjectedGallons:   prior.ProjectedGallons,
+		EndTime:            prior.EndTime,
+		WaitForCompletion:  prior.WaitForCompletion,
+		StartedAt:          prior.StartedAt,
+		FinishedAt:         prior.FinishedAt,
+		InterruptionReason: prior.InterruptionReason,
+		LastWatered:        types.StringNull(),
+		LastRunMinutes:     types.Float64Null(),
+		DefaultMinutes:     types.Int64Null(),
+		Timeouts:           zoneTimeouts(ctx, prior.Timeouts),
+	}
+}
+
+// zoneTimeouts converts a timeouts block of an earlier schema version,
+// keeping the timeouts the current block still has.
+func zoneTimeouts(ctx context.Context, prior types.Object) timeouts.Value {
+	value := zoneTimeoutsNull()
+	if prior.IsNull() || prior.IsUnknown() {
+		return value
+	}
+	attrTypes := value.Object.AttributeTypes(ctx)
+	attrs := make(map[string]attr.Value, len(attrTypes))
+	for name := range attrTypes {
+		attrs[name] = types.StringNull()
+		if v, ok := prior.Attributes()[name]; ok {
+			attrs[name] = v
+		}
+	}
+	value.Object = types.ObjectValueMust(attrTypes, attrs)
+	return value
+}
+
+// rfc3339LastUpdated converts a last_updated value written in RFC 850 format,
+// as before schema version 2, to RFC 3339.
+func rfc3339LastUpdated(lastUpdated types.String) types.String {
+	t, err := time.Parse(time.RFC850, lastUpdated.ValueString())
+	if err != nil {
+		return lastUpdated
+	}
+	return types.StringValue(t.Format(time.RFC3339))
+}
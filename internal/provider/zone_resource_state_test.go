@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestUpgradeZoneStateV0(t *testing.T) {
+	got, diags := upgradeZoneStateV0(context.Background(), zoneResourceModelV0{
+		ID:          types.StringValue("5"),
+		LastUpdated: types.StringValue("Monday, 03-Jun-24 05:00:00 UTC"),
+		Minutes:     types.StringValue("15"),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	want := zoneResourceModel{
+		ID:                 types.Int64Value(5),
+		LastUpdated:        types.StringValue("2024-06-03T05:00:00Z"),
+		Minutes:            types.Int64Value(15),
+		CycleMinutes:       types.Int64Null(),
+		SoakMinutes:        types.Int64Null(),
+		Cycles:             cyclesValue([]zoneCycle{{Minutes: 15}}),
+		TotalDuration:      types.Int64Value(15),
+		FlowRate:           types.Float64Null(),
+		TotalRuntime:       types.Int64Value(15),
+		ProjectedGallons:   types.Float64Null(),
+		EndTime:            types.StringValue("2024-06-03T05:15:00Z"),
+		WaitForCompletion:  types.BoolNull(),
+		StartedAt:          types.StringNull(),
+		FinishedAt:         types.StringNull(),
+		InterruptionReason: types.StringNull(),
+		LastWatered:        types.StringNull(),
+		LastRunMinutes:     types.Float64Null(),
+		DefaultMinutes:     types.Int64Null(),
+		Timeouts:           zoneTimeoutsNull(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	_, diags = upgradeZoneStateV0(context.Background(), zoneResourceModelV0{
+		ID:          types.StringValue("front"),
+		LastUpdated: types.StringNull(),
+		Minutes:     types.StringValue("15"),
+	})
+	if !diags.HasError() {
+		t.Error("expected an error for a non-numeric id")
+	}
+}
+
+func TestRFC3339LastUpdated(t *testing.T) {
+	tests := map[string]types.String{
+		"2024-06-03T05:00:00Z":      types.StringValue("Monday, 03-Jun-24 05:00:00 UTC"),
+		"2024-06-03T05:00:00+02:00": types.StringValue("2024-06-03T05:00:00+02:00"),
+		"":                          types.StringNull(),
+	}
+	for want, in := range tests {
+		if got := rfc3339LastUpdated(in); got.ValueString() != want || got.IsNull() != in.IsNull() {
+			t.Errorf("rfc3339LastUpdated(%s) = %s, want %q", in, got, want)
+		}
+	}
+}
+
+// zoneStateFromServer decodes a state returned by the provider server into
+// the zone model.
+func zoneStateFromServer(t *testing.T, state *tfprotov6.DynamicValue) zoneResourceModel {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	(&zoneResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	raw, err := state.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var model zoneResourceModel
+	if diags := (tfsdk.State{Schema: schemaResp.Schema, Raw: raw}).Get(ctx, &model); diags.HasError() {
+		t.Fatal(diags)
+	}
+	return model
+}
+
+func TestZoneResourceUpgradeStateV1(t *testing.T) {
+	ctx := context.Background()
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "bhyve_zone",
+		Version:  1,
+		RawState: &tfprotov6.RawState{JSON: []byte(`{
+			"id": 2, "minutes": 10, "flow_rate": 1.5, "total_runtime": 10, "projected_gallons": 15,
+			"last_updated": "Monday, 03-Jun-24 05:00:00 UTC", "end_time": "2024-06-03T05:10:00Z",
+			"wait_for_completion": true, "started_at": "2024-06-03T05:00:00Z", "finished_at": "2024-06-03T05:10:00Z",
+			"interruption_reason": null,
+			"timeouts": {"create": "20m", "read": null, "update": "1m", "delete": null}
+		}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("%s: %s", d.Summary, d.Detail)
+	}
+
+	got := zoneStateFromServer(t, resp.UpgradedState)
+	create, _ := got.Timeouts.Create(ctx, 0)
+	update, _ := got.Timeouts.Update(ctx, 0)
+	if got.LastUpdated.ValueString() != "2024-06-03T05:00:00Z" || got.FinishedAt.ValueString() != "2024-06-03T05:10:00Z" ||
+		got.ProjectedGallons.ValueFloat64() != 15 || got.TotalDuration.ValueInt64() != 10 || create != 20*time.Minute || update != time.Minute {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestZoneResourceMoveState(t *testing.T) {
+	ctx := context.Background()
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		version int64
+		state   string
+		wantID  int64
+	}{
+		{
+			name:    "version 0",
+			address: "registry.terraform.io/gillcaleb/bhyve",
+			state:   `{"id": "3", "minutes": "15", "last_updated": "Monday, 03-Jun-24 05:00:00 UTC"}`,
+			wantID:  3,
+		},
+		{
+			name:    "current version",
+			address: "github.com/gillcaleb/bhyve",
+			version: 2,
+			state: `{"id": 4, "minutes": 20, "total_runtime": 20, "total_duration": 20,
+				"last_updated": "2024-06-03T05:00:00Z", "end_time": "2024-06-03T05:20:00Z"}`,
+			wantID: 4,
+		},
+		{
+			name:    "other provider",
+			address: "registry.terraform.io/someone/bhyve",
+			state:   `{"id": "3", "minutes": "15", "last_updated": "Monday, 03-Jun-24 05:00:00 UTC"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.MoveResourceState(ctx, &tfprotov6.MoveResourceStateRequest{
+				SourceProviderAddress: tt.address,
+				SourceTypeName:        "bhyve_zone",
+				SourceSchemaVersion:   tt.version,
+				SourceState:           &tfprotov6.RawState{JSON: []byte(tt.state)},
+				TargetTypeName:        "bhyve_zone_run",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantID == 0 {
+				if len(resp.Diagnostics) == 0 {
+					t.Error("moved the state of another provider's resource")
+				}
+				return
+			}
+			for _, d := range resp.Diagnostics {
+				t.Fatalf("%s: %s", d.Summary, d.Detail)
+			}
+
+			got := zoneStateFromServer(t, resp.TargetState)
+			if got.ID.ValueInt64() != tt.wantID || got.LastUpdated.ValueString() != "2024-06-03T05:00:00Z" {
+				t.Errorf("got id %s and last_updated %s", got.ID, got.LastUpdated)
+			}
+		})
+	}
+}
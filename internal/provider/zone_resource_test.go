@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/time/rate"
+)
+
+func TestZoneResourceReadDevice(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc": `{"id":"abc","num_stations":4,"manual_preset_runtime_sec":600,
+			"zones":[{"station":1,"name":"Front"},{"station":2,"name":"Back"},{"station":4,"name":"Side"}]}`,
+		"/watering_events/abc": `[
+			{"start_time":"2024-06-01T05:00:00.000Z","irrigation":[{"station":1,"run_time":10}]},
+			{"start_time":"2024-06-02T05:00:00.000Z","irrigation":[
+				{"station":2,"run_time":5},
+				{"station":1,"run_time":7.5,"start_time":"2024-06-02T05:05:00.000Z"}
+			]}
+		]`,
+	})
+	r := &zoneResource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+	ctx := context.Background()
+
+	model := zoneResourceModel{ID: types.Int64Value(1)}
+	found, err := r.readDevice(ctx, &model)
+	if err != nil || !found {
+		t.Fatalf("got found %t, error %v", found, err)
+	}
+	if got := model.LastWatered.ValueString(); got != "2024-06-02T05:05:00Z" {
+		t.Errorf("last_watered = %s", got)
+	}
+	if got := model.LastRunMinutes.ValueFloat64(); got != 7.5 {
+		t.Errorf("last_run_minutes = %v", got)
+	}
+	if got := model.DefaultMinutes.ValueInt64(); got != 10 {
+		t.Errorf("default_minutes = %d", got)
+	}
+
+	// Without its own start time, a watering started with its event.
+	model = zoneResourceModel{ID: types.Int64Value(2)}
+	if _, err := r.readDevice(ctx, &model); err != nil {
+		t.Fatal(err)
+	}
+	if got := model.LastWatered.ValueString(); got != "2024-06-02T05:00:00Z" {
+		t.Errorf("last_watered = %s", got)
+	}
+
+	// A station the device has but never watered.
+	model = zoneResourceModel{ID: types.Int64Value(4)}
+	if found, err := r.readDevice(ctx, &model); err != nil || !found {
+		t.Fatalf("station 4: got found %t, error %v", found, err)
+	}
+	if !model.LastWatered.IsNull() || !model.LastRunMinutes.IsNull() {
+		t.Errorf("got last_watered %s and last_run_minutes %s for a station never watered", model.LastWatered, model.LastRunMinutes)
+	}
+
+	// Stations and devices that are gone.
+	model = zoneResourceModel{ID: types.Int64Value(3)}
+	if found, err := r.readDevice(ctx, &model); err != nil || found {
+		t.Errorf("station 3: got found %t, error %v", found, err)
+	}
+	r.data.deviceID = "gone"
+	if found, err := r.readDevice(ctx, &model); err != nil || found {
+		t.Errorf("missing device: got found %t, error %v", found, err)
+	}
+}
+
+// fakeClient records the stations started through it.
+type fakeClient struct {
+	starts chan int
+}
+
+func (c *fakeClient) Sync() error { return nil }
+
+func (c *fakeClient) StartZone(station, minutes int) error {
+	c.starts <- station
+	return nil
+}
+
+// createZoneRun plans a 10 minute run of station and creates it.
+func createZoneRun(t *testing.T, r *zoneResource, station int64) resource.CreateResponse {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	model := zoneResourceModel{
+		ID:       types.Int64Value(station),
+		Minutes:  types.Int64Value(10),
+		Cycles:   types.ListNull(types.ObjectType{AttrTypes: zoneCycleAttrTypes}),
+		Timeouts: zoneTimeoutsNull(),
+	}
+	raw := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := raw.Set(ctx, model); diags.HasError() {
+		t.Fatalf("building plan: %v", diags)
+	}
+
+	resp := resource.CreateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw.Raw}}, &resp)
+	return resp
+}
+
+func TestZoneResourceCreate(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc":         `{"id":"abc","num_stations":2,"zones":[{"station":1},{"station":2}]}`,
+		"/watering_events/abc": `[{"start_time":"2024-06-01T05:00:00.000Z","irrigation":[{"station":1,"run_time":10}]}]`,
+	})
+	f := newFakeEvents(t)
+	c := &fakeClient{starts: make(chan int, 2)}
+	defer close(c.starts)
+	r := &zoneResource{typeName: "_zone_run", data: &bhyveProviderData{
+		client:     c,
+		deviceID:   "abc",
+		api:        api,
+		retry:      retryPolicy{maxElapsed: time.Second},
+		limiter:    rate.NewLimiter(rate.Inf, 1),
+		events:     f.hub(),
+		guardrails: &guardrails{},
+	}}
+
+	// The fake device acknowledges each run by starting its station.
+	go func() {
+		conn := <-f.conns
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		for station := range c.starts {
+			_ = conn.WriteJSON(map[string]interface{}{
+				"event":           eventWateringInProgress,
+				"device_id":       "abc",
+				"current_station": station,
+			})
+		}
+	}()
+
+	// A station the device does not have fails before anything is sent.
+	resp := createZoneRun(t, r, 3)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Zone Not Found" {
+		t.Fatalf("got %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("state was written for a station the device does not have")
+	}
+	if len(c.starts) != 0 {
+		t.Errorf("station %d was started", <-c.starts)
+	}
+
+	resp = createZoneRun(t, r, 1)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	var state zoneResourceModel
+	if diags := resp.State.Get(context.Background(), &state); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if state.StartedAt.IsNull() || state.LastRunMinutes.ValueFloat64() != 10 {
+		t.Errorf("got started_at %s and last_run_minutes %s", state.StartedAt, state.LastRunMinutes)
+	}
+}
+
+func TestZoneResourceCreateMissingStation(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc":         `{"id":"abc","num_stations":2,"zones":[{"station":1},{"station":2}]}`,
+		"/watering_events/abc": `[]`,
+	})
+	log := filepath.Join(t.TempDir(), "commands.jsonl")
+	r := &zoneResource{typeName: "_zone_run", data: &bhyveProviderData{
+		deviceID: "abc",
+		api:      api,
+		retry:    retryPolicy{maxElapsed: time.Second},
+		limiter:  rate.NewLimiter(rate.Inf, 1),
+		dryRun:   &dryRunRecorder{path: log},
+	}}
+
+	resp := createZoneRun(t, r, 3)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Zone Not Found" {
+		t.Fatalf("got %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("state was written for a station the device does not have")
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Errorf("a command was recorded for a station the device does not have: %v", err)
+	}
+}
+
+func TestZoneResourceModifyPlanPolicies(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc": `{"id":"abc","num_stations":4,"zones":[{"station":1},{"station":2},{"station":3}]}`,
+	})
+	r := &zoneResource{typeName: "_zone_run", data: &bhyveProviderData{
+		deviceID:     "abc",
+		stationCount: 4,
+		api:          api,
+		guardrails:   &guardrails{maxZoneMinutes: 20},
+	}}
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	value := func(id, minutes int64) tftypes.Value {
+		model := zoneResourceModel{
+			ID:       types.Int64Value(id),
+			Minutes:  types.Int64Value(minutes),
+			Cycles:   types.ListNull(types.ObjectType{AttrTypes: zoneCycleAttrTypes}),
+			Timeouts: zoneTimeoutsNull(),
+		}
+		raw := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+		if diags := raw.Set(ctx, model); diags.HasError() {
+			t.Fatalf("building value: %v", diags)
+		}
+		return raw.Raw
+	}
+	null := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+
+	// The run in state started before the policies were tightened.
+	tests := []struct {
+		name        string
+		state, plan tftypes.Value
+		wantError   string
+	}{
+		{name: "no change", state: value(5, 30), plan: value(5, 30)},
+		{name: "create", state: null, plan: value(3, 30), wantError: "Run Blocked by max_zone_minutes"},
+		{name: "minutes changed", state: value(3, 30), plan: value(3, 25), wantError: "Run Blocked by max_zone_minutes"},
+		{name: "station changed", state: value(3, 10), plan: value(5, 10), wantError: "Station Not On Device"},
+		{name: "on device", state: null, plan: value(2, 10)},
+		{name: "not on device", state: null, plan: value(4, 10), wantError: "Station Not On Device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: tt.plan}
+			resp := resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: tt.state},
+				Plan:  plan,
+			}, &resp)
+			if tt.wantError == "" {
+				if resp.Diagnostics.HasError() {
+					t.Errorf("got %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != tt.wantError {
+				t.Errorf("got %v, want %q", resp.Diagnostics, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestZoneResourceReadOnly(t *testing.T) {
+	// The data has no client, so any command sent would panic.
+	r := &zoneResource{data: &bhyveProviderData{deviceID: "abc", readOnly: true}}
+	ctx := context.Background()
+
+	var create resource.CreateResponse
+	r.Create(ctx, resource.CreateRequest{}, &create)
+	var update resource.UpdateResponse
+	r.Update(ctx, resource.UpdateRequest{}, &update)
+	var del resource.DeleteResponse
+	r.Delete(ctx, resource.DeleteRequest{}, &del)
+
+	for name, diags := range map[string]diag.Diagnostics{
+		"create": create.Diagnostics,
+		"update": update.Diagnostics,
+		"delete": del.Diagnostics,
+	} {
+		if !diags.HasError() {
+			t.Errorf("%s was not refused", name)
+		}
+	}
+	if create.Diagnostics[0].Summary() != "Provider Is Read Only" {
+		t.Errorf("got %q", create.Diagnostics[0].Summary())
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// eventRainDelay is sent when a rain delay is set, which stops watering.
+const eventRainDelay = "rain_delay"
+
+// runProgressInterval is how often the remaining time of a run is logged
+// while waiting for it to finish.
+var runProgressInterval = time.Minute
+
+// runEndTolerance is how much earlier than planned a run may finish before
+// it is reported as interrupted. Devices round run times to the minute.
+const runEndTolerance = time.Minute
+
+// runResult is how a watched zone run ended.
+type runResult struct {
+	Finished time.Time
+	// Interruption says why the run ended early, empty when it ran in full.
+	Interruption string
+}
+
+// waitForRun waits on sub until the device reports that the run of station,
+// started at started for minutes, has ended, logging the remaining time
+// meanwhile. It returns the context error when ctx ends first.
+func waitForRun(ctx context.Context, sub *eventSubscription, station, minutes int64, started time.Time) (runResult, error) {
+	end := started.Add(time.Duration(minutes) * time.Minute)
+
+	ticker := time.NewTicker(runProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-sub.events:
+			now := time.Now()
+			reason, ended := runEnded(ev, station)
+			if !ended {
+				continue
+			}
+			if reason == "" && now.Before(end.Add(-runEndTolerance)) {
+				reason = fmt.Sprintf("stopped after %s of %d minutes", now.Sub(started).Round(time.Second), minutes)
+			}
+			return runResult{Finished: now, Interruption: reason}, nil
+		case now := <-ticker.C:
+			tflog.Info(ctx, "Waiting for zone run to finish", map[string]interface{}{
+				"station":   station,
+				"remaining": end.Sub(now).Round(time.Second).String(),
+			})
+		case <-ctx.Done():
+			return runResult{}, ctx.Err()
+		}
+	}
+}
+
+// runEnded reports whether ev ends a run of station and, when something other
+// than the run finishing ended it, why.
+func runEnded(ev deviceEvent, station int64) (string, bool) {
+	switch ev.Event {
+	case eventWateringComplete, eventDeviceIdle:
+		return "", ev.Station == 0 || ev.Station == station
+	case eventWateringInProgress:
+		if ev.Station != 0 && ev.Station != station {
+			return fmt.Sprintf("station %d started", ev.Station), true
+		}
+	case eventChangeMode:
+		if mode, _ := ev.Raw["mode"].(string); mode != "" && mode != "manual" {
+			return fmt.Sprintf("device switched to %s mode", mode), true
+		}
+	case eventRainDelay:
+		return "rain delay set", true
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitForRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		started time.Time
+		events  []deviceEvent
+		want    string
+	}{
+		{
+			name:    "ran in full",
+			started: time.Now().Add(-10 * time.Minute),
+			events: []deviceEvent{
+				{Event: eventWateringInProgress, Station: 2},
+				{Event: eventWateringComplete, Station: 2},
+			},
+		},
+		{
+			name:    "other station finishing is ignored",
+			started: time.Now().Add(-10 * time.Minute),
+			events: []deviceEvent{
+				{Event: eventWateringComplete, Station: 3},
+				{Event: eventDeviceIdle},
+			},
+		},
+		{
+			name:    "stopped early",
+			started: time.Now().Add(-2 * time.Minute),
+			events:  []deviceEvent{{Event: eventWateringComplete, Station: 2}},
+			want:    "stopped after 2m0s of 10 minutes",
+		},
+		{
+			name:    "another station started",
+			started: time.Now(),
+			events:  []deviceEvent{{Event: eventWateringInProgress, Station: 4}},
+			want:    "station 4 started",
+		},
+		{
+			name:    "switched off",
+			started: time.Now(),
+			events: []deviceEvent{
+				{Event: eventChangeMode, Raw: map[string]interface{}{"mode": "manual"}},
+				{Event: eventChangeMode, Raw: map[string]interface{}{"mode": "off"}},
+			},
+			want: "device switched to off mode",
+		},
+		{
+			name:    "rain delay",
+			started: time.Now(),
+			events:  []deviceEvent{{Event: eventRainDelay}},
+			want:    "rain delay set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &eventSubscription{events: make(chan deviceEvent, len(tt.events))}
+			for _, ev := range tt.events {
+				sub.events <- ev
+			}
+
+			result, err := waitForRun(context.Background(), sub, 2, 10, tt.started)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Interruption != tt.want {
+				t.Errorf("got interruption %q, want %q", result.Interruption, tt.want)
+			}
+			if result.Finished.IsZero() {
+				t.Error("finish time not recorded")
+			}
+		})
+	}
+}
+
+func TestWaitForRunTimeout(t *testing.T) {
+	sub := &eventSubscription{events: make(chan deviceEvent)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := waitForRun(ctx, sub, 2, 10, time.Now()); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+}
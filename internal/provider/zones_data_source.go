@@ -0,0 +1,265 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &zonesDataSource{}
+	_ datasource.DataSourceWithConfigure = &zonesDataSource{}
+)
+
+// NewZonesDataSource returns the data source listing the zones of the device.
+func NewZonesDataSource() datasource.DataSource {
+	return &zonesDataSource{}
+}
+
+// zonesDataSource is the data source implementation.
+type zonesDataSource struct {
+	data *bhyveProviderData
+}
+
+type zonesDataSourceModel struct {
+	NameRegex types.String       `tfsdk:"name_regex"`
+	Enabled   types.Bool         `tfsdk:"enabled"`
+	Program   types.String       `tfsdk:"program"`
+	Zones     []zoneSummaryModel `tfsdk:"zones"`
+}
+
+type zoneSummaryModel struct {
+	Station       types.Int64    `tfsdk:"station"`
+	Name          types.String   `tfsdk:"name"`
+	Enabled       types.Bool     `tfsdk:"enabled"`
+	SmartWatering types.Bool     `tfsdk:"smart_watering"`
+	FlowRate      types.Float64  `tfsdk:"flow_rate"`
+	Programs      []types.String `tfsdk:"programs"`
+	Landscape     landscapeModel `tfsdk:"landscape"`
+}
+
+// landscapeModel summarizes what a zone waters. Attributes are null when the
+// device does not report them.
+type landscapeModel struct {
+	CropType      types.String `tfsdk:"crop_type"`
+	SoilType      types.String `tfsdk:"soil_type"`
+	NozzleType    types.String `tfsdk:"nozzle_type"`
+	SprinklerType types.String `tfsdk:"sprinkler_type"`
+	SlopeType     types.String `tfsdk:"slope_type"`
+	ExposureType  types.String `tfsdk:"exposure_type"`
+}
+
+// Metadata returns the data source type name.
+func (d *zonesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zones"
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *zonesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*bhyveProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *bhyveProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.data = data
+}
+
+// Schema defines the schema for the data source.
+func (d *zonesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	landscapeAttribute := func(description string) schema.StringAttribute {
+		return schema.StringAttribute{Computed: true, Description: description}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Lists the zones of the device, optionally filtered.",
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list zones whose name matches this regular expression.",
+				Validators:  []validator.String{regexValidator{}},
+			},
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only list zones that are enabled, or disabled when false.",
+			},
+			"program": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list zones watered by this program, A to D.",
+				Validators:  []validator.String{programLetterValidator()},
+			},
+			"zones": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Zones of the device in station order.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"station": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Station number of the zone.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the zone.",
+						},
+						"enabled": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the zone is enabled.",
+						},
+						"smart_watering": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether smart watering adjusts the zone's runs.",
+						},
+						"flow_rate": schema.Float64Attribute{
+							Computed:    true,
+							Description: "Flow rate of the zone in gallons per minute, null when not set on the device.",
+						},
+						"programs": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "Programs that water the zone.",
+						},
+						"landscape": schema.SingleNestedAttribute{
+							Computed:    true,
+							Description: "What the zone waters, as set up in the B-hyve app.",
+							Attributes: map[string]schema.Attribute{
+								"crop_type":      landscapeAttribute("Plants in the zone, such as \"cool_season_grass\"."),
+								"soil_type":      landscapeAttribute("Soil of the zone."),
+								"nozzle_type":    landscapeAttribute("Nozzles watering the zone."),
+								"sprinkler_type": landscapeAttribute("Sprinklers watering the zone."),
+								"slope_type":     landscapeAttribute("Slope of the zone."),
+								"exposure_type":  landscapeAttribute("Sun exposure of the zone."),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *zonesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state zonesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	device, err := d.data.api.device(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Zones", "Could not read the device: "+err.Error())
+		return
+	}
+	programs, err := d.data.api.programs(ctx, d.data.deviceID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Reading Zones", "Could not read the device programs: "+err.Error())
+		return
+	}
+
+	var filter zoneFilter
+	if !state.NameRegex.IsNull() {
+		// The value is checked by regexValidator.
+		filter.name = regexp.MustCompile(state.NameRegex.ValueString())
+	}
+	if !state.Enabled.IsNull() {
+		enabled := state.Enabled.ValueBool()
+		filter.enabled = &enabled
+	}
+	filter.program = state.Program.ValueString()
+
+	state.Zones = []zoneSummaryModel{}
+	for _, zone := range filter.apply(device.Zones, programs) {
+		state.Zones = append(state.Zones, zoneSummary(zone, programs))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// zoneFilter selects zones. Unset fields select every zone.
+type zoneFilter struct {
+	name    *regexp.Regexp
+	enabled *bool
+	program string
+}
+
+func (f zoneFilter) apply(zones []apiZone, programs []apiProgram) []apiZone {
+	var selected []apiZone
+	for _, zone := range zones {
+		if f.name != nil && !f.name.MatchString(zone.Name) {
+			continue
+		}
+		if f.enabled != nil && zone.enabled() != *f.enabled {
+			continue
+		}
+		if f.program != "" && !inProgram(zone.Station, f.program, programs) {
+			continue
+		}
+		selected = append(selected, zone)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Station < selected[j].Station
+	})
+	return selected
+}
+
+// inProgram reports whether the program with the given letter waters
+// station.
+func inProgram(station int64, letter string, programs []apiProgram) bool {
+	for _, p := range programs {
+		if p.letter() == letter && p.hasStation(station) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneSummary maps a zone to its data source model.
+func zoneSummary(zone apiZone, programs []apiProgram) zoneSummaryModel {
+	summary := zoneSummaryModel{
+		Station:       types.Int64Value(zone.Station),
+		Name:          types.StringValue(zone.Name),
+		Enabled:       types.BoolValue(zone.enabled()),
+		SmartWatering: types.BoolValue(zone.SmartWateringEnabled),
+		FlowRate:      types.Float64PointerValue(zone.FlowRate),
+		Programs:      []types.String{},
+		Landscape: landscapeModel{
+			CropType:      optionalString(zone.CropType),
+			SoilType:      optionalString(zone.SoilType),
+			NozzleType:    optionalString(zone.NozzleType),
+			SprinklerType: optionalString(zone.SprinklerType),
+			SlopeType:     optionalString(zone.SlopeType),
+			ExposureType:  optionalString(zone.ExposureType),
+		},
+	}
+	for _, p := range programs {
+		if p.hasStation(zone.Station) {
+			summary.Programs = append(summary.Programs, types.StringValue(p.letter()))
+		}
+	}
+	sort.Slice(summary.Programs, func(i, j int) bool {
+		return summary.Programs[i].ValueString() < summary.Programs[j].ValueString()
+	})
+	return summary
+}
+
+// optionalString returns s, or null when it is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
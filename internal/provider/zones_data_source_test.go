@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// readDataSource runs Read on ds with config, which holds the model of the
+// data source, and decodes the resulting state into out.
+func readDataSource(t *testing.T, ds datasource.DataSource, config, out interface{}) {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	raw := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	if diags := raw.Set(ctx, config); diags.HasError() {
+		t.Fatalf("building config: %v", diags)
+	}
+
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+	ds.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw.Raw}}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", resp.Diagnostics)
+	}
+	if diags := resp.State.Get(ctx, out); diags.HasError() {
+		t.Fatalf("decoding state: %v", diags)
+	}
+}
+
+func TestZonesDataSource(t *testing.T) {
+	api := newFakeAPI(t, map[string]string{
+		"/devices/abc": `{"id":"abc","zones":[
+			{"station":2,"name":"Back Lawn","smart_watering_enabled":true,"flow_rate":3.5,"crop_type":"cool_season_grass"},
+			{"station":1,"name":"Front Lawn"},
+			{"station":3,"name":"Drip Beds","enabled":false}
+		]}`,
+		"/sprinkler_timer_programs": `[
+			{"program":"b","run_times":[{"station":2,"run_time":10}]},
+			{"program":"a","run_times":[{"station":1,"run_time":10},{"station":2,"run_time":5}]}
+		]`,
+	})
+	ds := &zonesDataSource{data: &bhyveProviderData{deviceID: "abc", api: api}}
+
+	tests := []struct {
+		name   string
+		config zonesDataSourceModel
+		want   []int64
+	}{
+		{name: "all", want: []int64{1, 2, 3}},
+		{name: "name", config: zonesDataSourceModel{NameRegex: types.StringValue("Lawn$")}, want: []int64{1, 2}},
+		{name: "enabled", config: zonesDataSourceModel{Enabled: types.BoolValue(false)}, want: []int64{3}},
+		{name: "program", config: zonesDataSourceModel{Program: types.StringValue("B")}, want: []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got zonesDataSourceModel
+			readDataSource(t, ds, &tt.config, &got)
+
+			var stations []int64
+			for _, zone := range got.Zones {
+				stations = append(stations, zone.Station.ValueInt64())
+			}
+			if len(stations) != len(tt.want) {
+				t.Fatalf("got stations %v, want %v", stations, tt.want)
+			}
+			for i := range stations {
+				if stations[i] != tt.want[i] {
+					t.Fatalf("got stations %v, want %v", stations, tt.want)
+				}
+			}
+		})
+	}
+
+	var got zonesDataSourceModel
+	readDataSource(t, ds, &zonesDataSourceModel{Program: types.StringValue("A")}, &got)
+	back := got.Zones[1]
+	if back.Name.ValueString() != "Back Lawn" || !back.SmartWatering.ValueBool() || back.FlowRate.ValueFloat64() != 3.5 {
+		t.Errorf("got %+v", back)
+	}
+	if len(back.Programs) != 2 || back.Programs[0].ValueString() != "A" || back.Programs[1].ValueString() != "B" {
+		t.Errorf("got programs %v", back.Programs)
+	}
+	if back.Landscape.CropType.ValueString() != "cool_season_grass" || !back.Landscape.SoilType.IsNull() {
+		t.Errorf("got landscape %+v", back.Landscape)
+	}
+	if front := got.Zones[0]; !front.FlowRate.IsNull() || !front.Enabled.ValueBool() {
+		t.Errorf("got %+v", front)
+	}
+}